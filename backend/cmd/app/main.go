@@ -8,6 +8,7 @@ import (
 
 	"secure-messaging-backend/internal/api"
 	"secure-messaging-backend/internal/config"
+	"secure-messaging-backend/internal/jobs"
 	"secure-messaging-backend/internal/logger"
 	"secure-messaging-backend/internal/store"
 )
@@ -31,7 +32,9 @@ func main() {
 	defer db.Close()
 
 	// Router / Server
-	e := api.NewServer(cfg, logg, db)
+	runner := jobs.NewRunner(logg)
+	e := api.NewServer(cfg, logg, db, runner)
+	runner.Start(ctx)
 
 	addr := ":" + cfg.HTTPPort
 	if v := os.Getenv("PORT"); v != "" {
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
+)
+
+// adminReasonReq is the optional body of admin actions; the reason goes to
+// the admin audit log.
+type adminReasonReq struct {
+	Reason *string `json:"reason"`
+}
+
+type adminTransferReq struct {
+	UserID int64   `json:"user_id"`
+	Reason *string `json:"reason"`
+}
+
+// adminErrStatus maps errors from admin actions to a response status.
+func adminErrStatus(err error) int {
+	if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrGroupNotFound) { return http.StatusNotFound }
+	return http.StatusBadRequest
+}
+
+func adminUserResp(u store.User) echo.Map {
+	return echo.Map{
+		"id": u.ID,
+		"email": u.Email,
+		"display_name": u.DisplayName,
+		"is_admin": u.IsAdmin,
+		"last_seen_at": u.LastSeenAt,
+		"deactivated_at": u.DeactivatedAt,
+		"suspended_at": u.SuspendedAt,
+		"suspension_reason": u.SuspensionReason,
+		"created_at": u.CreatedAt,
+	}
+}
+
+func AdminSearchUsersHandler(s *service.AdminService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		suspended := c.QueryParam("suspended") == "true"
+		page, err := s.SearchUsers(c.Request().Context(), c.QueryParam("q"), suspended, c.QueryParam("cursor"), queueLimit(c))
+		if errors.Is(err, store.ErrInvalidCursor) { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(page.Users))
+		for _, u := range page.Users {
+			out = append(out, adminUserResp(u))
+		}
+		return c.JSON(http.StatusOK, echo.Map{"users": out, "next_cursor": page.NextCursor})
+	}
+}
+
+func SuspendUserHandler(s *service.AdminService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		target, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"}) }
+		req := new(adminReasonReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		if err := s.Suspend(c.Request().Context(), uid, target, req.Reason); err != nil {
+			return c.JSON(adminErrStatus(err), echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func UnsuspendUserHandler(s *service.AdminService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		target, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"}) }
+		req := new(adminReasonReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		if err := s.Unsuspend(c.Request().Context(), uid, target, req.Reason); err != nil {
+			return c.JSON(adminErrStatus(err), echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func AdminDeleteGroupHandler(s *service.AdminService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(adminReasonReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		if err := s.DeleteGroup(c.Request().Context(), uid, gid, req.Reason); err != nil {
+			return c.JSON(adminErrStatus(err), echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func AdminTransferOwnerHandler(s *service.AdminService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(adminTransferReq)
+		if err := c.Bind(req); err != nil || req.UserID == 0 { return c.JSON(http.StatusBadRequest, echo.Map{"error": "user_id required"}) }
+		if err := s.TransferOwnership(c.Request().Context(), uid, gid, req.UserID, req.Reason); err != nil {
+			return c.JSON(adminErrStatus(err), echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func AdminStatsHandler(s *service.AdminService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		st, err := s.Stats(c.Request().Context())
+		if err != nil { return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusOK, st)
+	}
+}
+
+func AdminAuditLogHandler(s *service.AdminService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		f := store.AdminAuditFilter{Action: c.QueryParam("action")}
+		var err error
+		if v := c.QueryParam("actor_id"); v != "" {
+			if f.ActorID, err = strconv.ParseInt(v, 10, 64); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid actor_id"}) }
+		}
+		if v := c.QueryParam("target_user_id"); v != "" {
+			if f.TargetUserID, err = strconv.ParseInt(v, 10, 64); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid target_user_id"}) }
+		}
+		if v := c.QueryParam("target_group_id"); v != "" {
+			if f.TargetGroupID, err = strconv.ParseInt(v, 10, 64); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid target_group_id"}) }
+		}
+		page, err := s.AuditLog(c.Request().Context(), f, c.QueryParam("cursor"), queueLimit(c))
+		if errors.Is(err, store.ErrInvalidCursor) { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(page.Entries))
+		for _, e := range page.Entries {
+			out = append(out, echo.Map{
+				"id": e.ID,
+				"actor_id": e.ActorID,
+				"action": e.Action,
+				"target_user_id": e.TargetUserID,
+				"target_group_id": e.TargetGroupID,
+				"reason": e.Reason,
+				"details": rawJSON(e.Details),
+				"created_at": e.CreatedAt,
+			})
+		}
+		return c.JSON(http.StatusOK, echo.Map{"entries": out, "next_cursor": page.NextCursor})
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
+)
+
+type appealReq struct {
+	Message string `json:"message"`
+}
+
+type decideAppealReq struct {
+	Reason *string `json:"reason"`
+}
+
+func appealResp(a store.BanAppeal) echo.Map {
+	return echo.Map{
+		"id": a.ID,
+		"group_id": a.GroupID,
+		"user_id": a.UserID,
+		"message": a.Message,
+		"status": a.Status,
+		"decided_by": a.DecidedBy,
+		"decision_reason": a.DecisionReason,
+		"decided_at": a.DecidedAt,
+		"created_at": a.CreatedAt,
+	}
+}
+
+// AppealBanHandler lives under /users/me rather than the group routes, which
+// hide secret groups from non-members, banned users included.
+func AppealBanHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("group_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(appealReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		a, err := s.AppealBan(c.Request().Context(), gid, uid, req.Message)
+		var cooldown *service.AppealCooldownError
+		if errors.As(err, &cooldown) {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(cooldown.Seconds()))
+			return c.JSON(http.StatusTooManyRequests, echo.Map{"error": err.Error(), "code": "appeal_cooldown", "retry_at": cooldown.Until})
+		}
+		if errors.Is(err, store.ErrAppealOpen) { return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusCreated, appealResp(*a))
+	}
+}
+
+func ListMyAppealsHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		rows, err := s.MyAppeals(c.Request().Context(), uid)
+		if err != nil { return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(rows))
+		for _, a := range rows {
+			out = append(out, appealResp(a))
+		}
+		return c.JSON(http.StatusOK, echo.Map{"appeals": out})
+	}
+}
+
+func ListAppealsHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		page, err := s.ListAppeals(c.Request().Context(), gid, uid, c.QueryParam("status"), c.QueryParam("cursor"), queueLimit(c))
+		if errors.Is(err, store.ErrInvalidCursor) { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(page.Appeals))
+		for _, a := range page.Appeals {
+			out = append(out, appealResp(a))
+		}
+		return c.JSON(http.StatusOK, echo.Map{"appeals": out, "next_cursor": page.NextCursor})
+	}
+}
+
+func GrantAppealHandler(s *service.GroupService) echo.HandlerFunc { return decideAppeal(s.GrantAppeal) }
+
+func DenyAppealHandler(s *service.GroupService) echo.HandlerFunc { return decideAppeal(s.DenyAppeal) }
+
+// decideAppeal grants or denies an appeal, with a reason sent to the user.
+func decideAppeal(decide func(ctx context.Context, groupID, actorID, appealID int64, reason *string) error) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		aid, err := strconv.ParseInt(c.Param("appeal_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid appeal id"}) }
+		req := new(decideAppealReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		err = decide(c.Request().Context(), gid, uid, aid, req.Reason)
+		if errors.Is(err, service.ErrAppealNotFound) { return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		return c.NoContent(http.StatusNoContent)
+	}
+}
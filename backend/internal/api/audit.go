@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
+)
+
+// rawJSON passes a stored JSON document through unchanged; nil stays null.
+func rawJSON(s *string) json.RawMessage {
+	if s == nil { return nil }
+	return json.RawMessage(*s)
+}
+
+func AuditLogHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		in := service.AuditLogInput{GroupID: gid, RequesterID: uid, Action: c.QueryParam("action"), Cursor: c.QueryParam("cursor"), Limit: 50}
+		if v := c.QueryParam("actor_id"); v != "" {
+			if in.ActorID, err = strconv.ParseInt(v, 10, 64); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid actor_id"}) }
+		}
+		if v := c.QueryParam("target_user_id"); v != "" {
+			if in.TargetUserID, err = strconv.ParseInt(v, 10, 64); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid target_user_id"}) }
+		}
+		if v := c.QueryParam("since"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid since"}) }
+			in.Since = &t
+		}
+		if v := c.QueryParam("until"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid until"}) }
+			in.Until = &t
+		}
+		if l := c.QueryParam("limit"); l != "" {
+			if n, err := strconv.Atoi(l); err == nil { in.Limit = n }
+		}
+		page, err := s.AuditLog(c.Request().Context(), in)
+		if errors.Is(err, store.ErrInvalidCursor) { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(page.Entries))
+		for _, e := range page.Entries {
+			out = append(out, echo.Map{
+				"id": e.ID,
+				"actor_id": e.ActorID,
+				"action": e.Action,
+				"target_user_id": e.TargetUserID,
+				"target_id": e.TargetID,
+				"reason": e.Reason,
+				"before": rawJSON(e.Before),
+				"after": rawJSON(e.After),
+				"created_at": e.CreatedAt,
+			})
+		}
+		return c.JSON(http.StatusOK, echo.Map{"entries": out, "next_cursor": page.NextCursor})
+	}
+}
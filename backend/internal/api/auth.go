@@ -8,8 +8,9 @@ import (
 )
 
 type registerReq struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
+	Email       string `json:"email" validate:"required,email"`
+	DisplayName string `json:"display_name"`
+	Password    string `json:"password" validate:"required,min=8"`
 }
 
 type loginReq struct {
@@ -26,9 +27,9 @@ func RegisterHandler(s *service.AuthService) echo.HandlerFunc {
 		req := new(registerReq)
 		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
 		if req.Email == "" || req.Password == "" { return c.JSON(http.StatusBadRequest, echo.Map{"error": "email and password required"}) }
-		u, err := s.Register(c.Request().Context(), req.Email, req.Password)
+		u, err := s.Register(c.Request().Context(), req.Email, req.DisplayName, req.Password)
 		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
-		return c.JSON(http.StatusCreated, echo.Map{"id": u.ID, "email": u.Email})
+		return c.JSON(http.StatusCreated, echo.Map{"id": u.ID, "email": u.Email, "display_name": u.DisplayName})
 	}
 }
 
@@ -39,7 +40,7 @@ func LoginHandler(s *service.AuthService) echo.HandlerFunc {
 		u, pair, err := s.Login(c.Request().Context(), req.Email, req.Password)
 		if err != nil { return c.JSON(http.StatusUnauthorized, echo.Map{"error": err.Error()}) }
 		return c.JSON(http.StatusOK, echo.Map{
-			"user": echo.Map{"id": u.ID, "email": u.Email},
+			"user": echo.Map{"id": u.ID, "email": u.Email, "display_name": u.DisplayName},
 			"access_token": pair.AccessToken,
 			"refresh_token": pair.RefreshToken,
 		})
@@ -58,3 +59,14 @@ func RefreshHandler(s *service.AuthService) echo.HandlerFunc {
 		})
 	}
 }
+
+func DeactivateAccountHandler(s *service.AuthService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		if err := s.Deactivate(c.Request().Context(), uid); err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
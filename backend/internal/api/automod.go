@@ -0,0 +1,155 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
+)
+
+type automodRuleReq struct {
+	Kind      string `json:"kind"`
+	Pattern   string `json:"pattern"`
+	Threshold int    `json:"threshold"`
+	Action    string `json:"action"`
+}
+
+func (r *automodRuleReq) input() service.AutomodRuleInput {
+	return service.AutomodRuleInput{Kind: r.Kind, Pattern: r.Pattern, Threshold: r.Threshold, Action: r.Action}
+}
+
+type updateAutomodRuleReq struct {
+	Action  *string `json:"action"`
+	Enabled *bool   `json:"enabled"`
+}
+
+type testAutomodReq struct {
+	Text string          `json:"text"`
+	Rule *automodRuleReq `json:"rule"`
+}
+
+func automodRuleResp(r *store.AutomodRule) echo.Map {
+	return echo.Map{
+		"id": r.ID,
+		"kind": r.Kind,
+		"pattern": r.Pattern,
+		"threshold": r.Threshold,
+		"action": r.Action,
+		"enabled": r.Enabled,
+		"hit_count": r.HitCount,
+		"last_hit_at": r.LastHitAt,
+		"created_by": r.CreatedBy,
+		"created_at": r.CreatedAt,
+	}
+}
+
+func ListAutomodRulesHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		rules, err := s.ListAutomodRules(c.Request().Context(), gid, uid)
+		if err != nil { return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(rules))
+		for i := range rules { out = append(out, automodRuleResp(&rules[i])) }
+		return c.JSON(http.StatusOK, echo.Map{"rules": out})
+	}
+}
+
+func CreateAutomodRuleHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(automodRuleReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		r, err := s.CreateAutomodRule(c.Request().Context(), gid, uid, req.input())
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusCreated, automodRuleResp(r))
+	}
+}
+
+func UpdateAutomodRuleHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		rid, err := strconv.ParseInt(c.Param("rule_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid rule id"}) }
+		req := new(updateAutomodRuleReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		r, err := s.UpdateAutomodRule(c.Request().Context(), gid, uid, rid, req.Action, req.Enabled)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusOK, automodRuleResp(r))
+	}
+}
+
+func DeleteAutomodRuleHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		rid, err := strconv.ParseInt(c.Param("rule_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid rule id"}) }
+		if err := s.DeleteAutomodRule(c.Request().Context(), gid, uid, rid); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// TestAutomodHandler dry-runs text against the group's rules, or against a
+// draft rule given in the body.
+func TestAutomodHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(testAutomodReq)
+		if err := c.Bind(req); err != nil || req.Text == "" { return c.JSON(http.StatusBadRequest, echo.Map{"error": "text required"}) }
+		var draft *service.AutomodRuleInput
+		if req.Rule != nil {
+			in := req.Rule.input()
+			draft = &in
+		}
+		v, err := s.TestAutomod(c.Request().Context(), gid, uid, req.Text, draft)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		matched := make([]echo.Map, 0, len(v.Matched))
+		for _, r := range v.Matched {
+			matched = append(matched, echo.Map{"rule_id": r.ID, "kind": r.Kind, "pattern": r.Pattern, "action": r.Action})
+		}
+		var action *string
+		if v.Action != "" { action = &v.Action }
+		return c.JSON(http.StatusOK, echo.Map{"action": action, "matched": matched})
+	}
+}
+
+func FlaggedMessagesHandler(s *service.MessageService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		limit := 50
+		if l := c.QueryParam("limit"); l != "" {
+			if n, err := strconv.Atoi(l); err == nil { limit = n }
+		}
+		page, err := s.FlaggedMessages(c.Request().Context(), gid, uid, c.QueryParam("cursor"), limit)
+		if errors.Is(err, store.ErrInvalidCursor) { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(page.Messages))
+		for i := range page.Messages {
+			m := &page.Messages[i]
+			out = append(out, echo.Map{"message": toMessageResp(&m.MessageDTO), "rule_ids": m.RuleIDs, "flagged_at": m.FlaggedAt})
+		}
+		return c.JSON(http.StatusOK, echo.Map{"flags": out, "next_cursor": page.NextCursor})
+	}
+}
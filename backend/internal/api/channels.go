@@ -0,0 +1,157 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
+)
+
+type createChannelReq struct {
+	Name       string `json:"name"`
+	Restricted bool   `json:"restricted"`
+}
+
+type renameChannelReq struct {
+	Name string `json:"name"`
+}
+
+type reorderChannelsReq struct {
+	ChannelIDs []int64 `json:"channel_ids"`
+}
+
+type channelMemberReq struct {
+	UserID int64 `json:"user_id"`
+}
+
+func channelResp(ch *store.Channel) echo.Map {
+	return echo.Map{
+		"id": ch.ID,
+		"name": ch.Name,
+		"position": ch.Position,
+		"restricted": ch.Restricted,
+		"archived": ch.Archived(),
+		"created_at": ch.CreatedAt,
+	}
+}
+
+// channelIDs parses the group and channel ids of a channel route.
+func channelIDs(c echo.Context) (int64, int64, error) {
+	gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil { return 0, 0, errors.New("invalid group id") }
+	chID, err := strconv.ParseInt(c.Param("channel_id"), 10, 64)
+	if err != nil { return 0, 0, errors.New("invalid channel id") }
+	return gid, chID, nil
+}
+
+func channelErrStatus(err error) int {
+	if errors.Is(err, service.ErrChannelNotFound) { return http.StatusNotFound }
+	if errors.Is(err, store.ErrChannelExists) { return http.StatusConflict }
+	return http.StatusBadRequest
+}
+
+func ListChannelsHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		rows, err := s.ListChannels(c.Request().Context(), gid, uid, c.QueryParam("include_archived") == "true")
+		if err != nil { return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(rows))
+		for i := range rows { out = append(out, channelResp(&rows[i])) }
+		return c.JSON(http.StatusOK, echo.Map{"channels": out})
+	}
+}
+
+func CreateChannelHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(createChannelReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		ch, err := s.CreateChannel(c.Request().Context(), gid, uid, req.Name, req.Restricted)
+		if err != nil { return c.JSON(channelErrStatus(err), echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusCreated, channelResp(ch))
+	}
+}
+
+func RenameChannelHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, chID, err := channelIDs(c)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		req := new(renameChannelReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		if err := s.RenameChannel(c.Request().Context(), gid, uid, chID, req.Name); err != nil {
+			return c.JSON(channelErrStatus(err), echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// ArchiveChannelHandler archives on POST and restores on DELETE.
+func ArchiveChannelHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, chID, err := channelIDs(c)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		archived := c.Request().Method != http.MethodDelete
+		if err := s.SetChannelArchived(c.Request().Context(), gid, uid, chID, archived); err != nil {
+			return c.JSON(channelErrStatus(err), echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func ReorderChannelsHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(reorderChannelsReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		if err := s.ReorderChannels(c.Request().Context(), gid, uid, req.ChannelIDs); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func AddChannelMemberHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, chID, err := channelIDs(c)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		req := new(channelMemberReq)
+		if err := c.Bind(req); err != nil || req.UserID == 0 { return c.JSON(http.StatusBadRequest, echo.Map{"error": "user_id required"}) }
+		if err := s.AddChannelMember(c.Request().Context(), gid, uid, chID, req.UserID); err != nil {
+			return c.JSON(channelErrStatus(err), echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func RemoveChannelMemberHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, chID, err := channelIDs(c)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		target, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"}) }
+		if err := s.RemoveChannelMember(c.Request().Context(), gid, uid, chID, target); err != nil {
+			return c.JSON(channelErrStatus(err), echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
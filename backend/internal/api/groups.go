@@ -1,17 +1,53 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
 )
 
 type createGroupReq struct {
-	Name       string `json:"name"`
-	Type       string `json:"type"`
-	MaxMembers int    `json:"max_members"`
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	MaxMembers     int      `json:"max_members"`
+	MembersVisible bool     `json:"members_visible"`
+	Description    string   `json:"description"`
+	Tags           []string `json:"tags"`
+}
+
+type joinGroupReq struct {
+	Message string      `json:"message"`
+	Answers []answerReq `json:"answers"`
+}
+
+type answerReq struct {
+	QuestionID int64  `json:"question_id"`
+	Answer     string `json:"answer"`
+}
+
+type updateSettingsReq struct {
+	MembersVisible        *bool     `json:"members_visible"`
+	RejoinCooldownSeconds *int      `json:"rejoin_cooldown_seconds"`
+	AutoApproveDomains    *[]string `json:"auto_approve_domains"`
+	RequireJoinMessage    *bool     `json:"require_join_message"`
+	RequireVouch          *bool     `json:"require_vouch"`
+	WaitlistEnabled       *bool     `json:"waitlist_enabled"`
+	SuccessorID           *int64    `json:"successor_id"`
+	SuccessionRules       *[]string `json:"succession_rules"`
+	OwnerInactiveDays     *int      `json:"owner_inactive_days"`
+	AnnouncementOnly      *bool     `json:"announcement_only"`
+	SlowModeSeconds       *int      `json:"slow_mode_seconds"`
+	HoldNewMemberHours    *int      `json:"hold_new_member_hours"`
+	HoldMinApproved       *int      `json:"hold_min_approved_messages"`
+}
+
+type vouchReq struct {
+	UserID int64 `json:"user_id"`
 }
 
 type transferOwnerReq struct {
@@ -29,9 +65,17 @@ func CreateGroupHandler(s *service.GroupService) echo.HandlerFunc {
 		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
 		req := new(createGroupReq)
 		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
-		g, err := s.CreateGroup(c.Request().Context(), req.Name, uid, req.Type, req.MaxMembers)
+		g, err := s.CreateGroup(c.Request().Context(), service.CreateGroupInput{
+			Name:           req.Name,
+			OwnerID:        uid,
+			Type:           req.Type,
+			MaxMembers:     req.MaxMembers,
+			MembersVisible: req.MembersVisible,
+			Description:    req.Description,
+			Tags:           req.Tags,
+		})
 		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
-		return c.JSON(http.StatusCreated, echo.Map{"id": g.ID, "name": g.Name, "owner_id": g.OwnerID, "type": g.Type, "max_members": g.MaxMembers})
+		return c.JSON(http.StatusCreated, echo.Map{"id": g.ID, "name": g.Name, "owner_id": g.OwnerID, "type": g.Type, "max_members": g.MaxMembers, "members_visible": g.MembersVisible, "description": g.Description, "tags": g.Tags})
 	}
 }
 
@@ -42,23 +86,62 @@ func ListGroupsHandler(s *service.GroupService) echo.HandlerFunc {
 		if limitStr != "" {
 			if v, err := strconv.Atoi(limitStr); err == nil { limit = v }
 		}
-		pub, err := s.ListPublic(c.Request().Context(), limit)
+		uid, _ := GetUserID(c)
+		page, err := s.ListPublic(c.Request().Context(), uid, c.QueryParam("cursor"), limit)
+		if errors.Is(err, store.ErrInvalidCursor) { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
 		if err != nil { return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()}) }
+		pubOut := make([]echo.Map, 0, len(page.Groups))
+		for _, g := range page.Groups {
+			pubOut = append(pubOut, echo.Map{
+				"id": g.ID,
+				"name": g.Name,
+				"description": g.Description,
+				"tags": g.Tags,
+				"type": g.Type,
+				"max_members": g.MaxMembers,
+				"member_count": g.MemberCount,
+				"last_activity_at": g.LastActivityAt,
+				"archived": g.Archived(),
+				"membership": g.Membership,
+			})
+		}
+		return c.JSON(http.StatusOK, echo.Map{"public": pubOut, "next_cursor": page.NextCursor})
+	}
+}
+
+func SearchGroupsHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
 		uid, _ := GetUserID(c)
-		owned := []interface{}{}
-		if uid != 0 {
-			ogs, err := s.ListOwned(c.Request().Context(), uid)
-			if err == nil {
-				for _, g := range ogs {
-					owned = append(owned, echo.Map{"id": g.ID, "name": g.Name, "type": g.Type, "max_members": g.MaxMembers})
-				}
-			}
+		limit := 50
+		if l := c.QueryParam("limit"); l != "" {
+			if n, err := strconv.Atoi(l); err == nil { limit = n }
 		}
-		pubOut := []interface{}{}
-		for _, g := range pub {
-			pubOut = append(pubOut, echo.Map{"id": g.ID, "name": g.Name, "type": g.Type, "max_members": g.MaxMembers})
+		tags := c.QueryParams()["tag"]
+		if t := c.QueryParam("tags"); t != "" { tags = append(tags, strings.Split(t, ",")...) }
+		page, err := s.Search(c.Request().Context(), service.SearchGroupsInput{
+			CallerID: uid,
+			Query:    c.QueryParam("q"),
+			Tags:     tags,
+			Sort:     c.QueryParam("sort"),
+			Cursor:   c.QueryParam("cursor"),
+			Limit:    limit,
+		})
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(page.Groups))
+		for _, g := range page.Groups {
+			out = append(out, echo.Map{
+				"id": g.ID,
+				"name": g.Name,
+				"description": g.Description,
+				"tags": g.Tags,
+				"type": g.Type,
+				"max_members": g.MaxMembers,
+				"member_count": g.MemberCount,
+				"last_activity_at": g.LastActivityAt,
+				"archived": g.Archived(),
+			})
 		}
-		return c.JSON(http.StatusOK, echo.Map{"public": pubOut, "owned": owned})
+		return c.JSON(http.StatusOK, echo.Map{"groups": out, "next_cursor": page.NextCursor})
 	}
 }
 
@@ -69,8 +152,18 @@ func JoinGroupHandler(s *service.GroupService) echo.HandlerFunc {
 		gidStr := c.Param("id")
 		gid, err := strconv.ParseInt(gidStr, 10, 64)
 		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
-		status, err := s.Join(c.Request().Context(), gid, uid)
-		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		req := new(joinGroupReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		answers := make([]service.AnswerInput, 0, len(req.Answers))
+		for _, a := range req.Answers {
+			answers = append(answers, service.AnswerInput{QuestionID: a.QuestionID, Answer: a.Answer})
+		}
+		status, err := s.Join(c.Request().Context(), service.JoinInput{GroupID: gid, UserID: uid, Message: req.Message, Answers: answers})
+		if err != nil { return c.JSON(joinErrStatus(err), echo.Map{"error": err.Error()}) }
+		if pos, ok := strings.CutPrefix(status, "waitlisted:"); ok {
+			n, _ := strconv.Atoi(pos)
+			return c.JSON(http.StatusAccepted, echo.Map{"status": "waitlisted", "position": n})
+		}
 		return c.JSON(http.StatusOK, echo.Map{"status": status})
 	}
 }
@@ -98,10 +191,9 @@ func TransferOwnerHandler(s *service.GroupService) echo.HandlerFunc {
 		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
 		req := new(transferOwnerReq)
 		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
-		if err := s.TransferOwner(c.Request().Context(), gid, uid, req.NewOwnerID); err != nil {
-			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
-		}
-		return c.NoContent(http.StatusNoContent)
+		t, err := s.TransferOwner(c.Request().Context(), gid, uid, req.NewOwnerID)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusAccepted, transferResp(t))
 	}
 }
 
@@ -119,6 +211,32 @@ func DeleteGroupHandler(s *service.GroupService) echo.HandlerFunc {
 	}
 }
 
+func RestoreGroupHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		g, err := s.Restore(c.Request().Context(), gid, uid)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusOK, echo.Map{"id": g.ID, "name": g.Name, "type": g.Type, "owner_id": g.OwnerID})
+	}
+}
+
+func ArchiveGroupHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		archived := c.Request().Method != http.MethodDelete
+		if err := s.SetArchived(c.Request().Context(), gid, uid, archived); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
 func BanishHandler(s *service.GroupService) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		uid, ok := GetUserID(c)
@@ -135,3 +253,120 @@ func BanishHandler(s *service.GroupService) echo.HandlerFunc {
 		return c.NoContent(http.StatusNoContent)
 	}
 }
+
+func UpdateSettingsHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(updateSettingsReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		gs, err := s.UpdateSettings(c.Request().Context(), gid, uid, service.SettingsPatch{
+			MembersVisible:        req.MembersVisible,
+			RejoinCooldownSeconds: req.RejoinCooldownSeconds,
+			AutoApproveDomains:    req.AutoApproveDomains,
+			RequireJoinMessage:    req.RequireJoinMessage,
+			RequireVouch:          req.RequireVouch,
+			WaitlistEnabled:       req.WaitlistEnabled,
+			SuccessorID:           req.SuccessorID,
+			SuccessionRules:       req.SuccessionRules,
+			OwnerInactiveDays:     req.OwnerInactiveDays,
+			AnnouncementOnly:      req.AnnouncementOnly,
+			SlowModeSeconds:       req.SlowModeSeconds,
+			HoldNewMemberHours:    req.HoldNewMemberHours,
+			HoldMinApproved:       req.HoldMinApproved,
+		})
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusOK, settingsResp(gs))
+	}
+}
+
+func settingsResp(gs *store.GroupSettings) echo.Map {
+	return echo.Map{
+		"members_visible": gs.MembersVisible,
+		"rejoin_cooldown_seconds": gs.RejoinCooldownSeconds,
+		"auto_approve_domains": gs.AutoApproveDomains,
+		"require_join_message": gs.RequireJoinMessage,
+		"require_vouch": gs.RequireVouch,
+		"waitlist_enabled": gs.WaitlistEnabled,
+		"successor_id": gs.SuccessorID,
+		"succession_rules": gs.SuccessionRules,
+		"owner_inactive_days": gs.OwnerInactiveDays,
+		"announcement_only": gs.AnnouncementOnly,
+		"slow_mode_seconds": gs.SlowModeSeconds,
+		"hold_new_member_hours": gs.HoldNewMemberHours,
+		"hold_min_approved_messages": gs.HoldMinApproved,
+	}
+}
+
+func VouchHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(vouchReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		if req.UserID == 0 { return c.JSON(http.StatusBadRequest, echo.Map{"error": "user_id required"}) }
+		if err := s.Vouch(c.Request().Context(), gid, uid, req.UserID); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func RevokeVouchHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		target, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"}) }
+		if err := s.RevokeVouch(c.Request().Context(), gid, uid, target); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func UnbanHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		target, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"}) }
+		if err := s.Unban(c.Request().Context(), gid, uid, target); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func WaitlistPositionHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		st, err := s.WaitlistPosition(c.Request().Context(), gid, uid)
+		if err != nil { return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusOK, echo.Map{"position": st.Position, "length": st.Length})
+	}
+}
+
+func LeaveWaitlistHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		if err := s.LeaveWaitlist(c.Request().Context(), gid, uid); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
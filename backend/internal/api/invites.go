@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
+)
+
+type inviteUserReq struct {
+	UserID int64 `json:"user_id"`
+}
+
+type inviteLinkReq struct {
+	MaxUses        *int `json:"max_uses"`
+	ExpiresInHours int  `json:"expires_in_hours"`
+}
+
+func inviteResp(inv *store.Invite) echo.Map {
+	return echo.Map{
+		"id": inv.ID,
+		"group_id": inv.GroupID,
+		"inviter_id": inv.InviterID,
+		"invitee_id": inv.InviteeID,
+		"token": inv.Token,
+		"max_uses": inv.MaxUses,
+		"uses": inv.Uses,
+		"expires_at": inv.ExpiresAt,
+		"created_at": inv.CreatedAt,
+	}
+}
+
+// joinErrStatus maps errors from joining paths to a response status.
+func joinErrStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrGroupNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrGroupArchived):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+func InviteUserHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(inviteUserReq)
+		if err := c.Bind(req); err != nil || req.UserID == 0 { return c.JSON(http.StatusBadRequest, echo.Map{"error": "user_id required"}) }
+		inv, err := s.InviteUser(c.Request().Context(), gid, uid, req.UserID)
+		if err != nil { return c.JSON(joinErrStatus(err), echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusCreated, inviteResp(inv))
+	}
+}
+
+func CreateInviteLinkHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(inviteLinkReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		inv, err := s.CreateInviteLink(c.Request().Context(), gid, uid, req.MaxUses, time.Duration(req.ExpiresInHours)*time.Hour)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusCreated, inviteResp(inv))
+	}
+}
+
+func ListInvitesHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		rows, err := s.ListInvites(c.Request().Context(), gid, uid)
+		if err != nil { return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(rows))
+		for i := range rows { out = append(out, inviteResp(&rows[i])) }
+		return c.JSON(http.StatusOK, echo.Map{"invites": out})
+	}
+}
+
+func RevokeInviteHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		invID, err := strconv.ParseInt(c.Param("invite_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid invite id"}) }
+		if err := s.RevokeInvite(c.Request().Context(), gid, uid, invID); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func ListMyInvitesHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		rows, err := s.ListMyInvites(c.Request().Context(), uid)
+		if err != nil { return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(rows))
+		for i := range rows {
+			m := inviteResp(&rows[i].Invite)
+			m["group_name"] = rows[i].GroupName
+			out = append(out, m)
+		}
+		return c.JSON(http.StatusOK, echo.Map{"invites": out})
+	}
+}
+
+func AcceptInviteHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		invID, err := strconv.ParseInt(c.Param("invite_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid invite id"}) }
+		gid, err := s.AcceptInvite(c.Request().Context(), uid, invID)
+		if err != nil { return c.JSON(joinErrStatus(err), echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusOK, echo.Map{"status": "joined", "group_id": gid})
+	}
+}
+
+func DeclineInviteHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		invID, err := strconv.ParseInt(c.Param("invite_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid invite id"}) }
+		if err := s.DeclineInvite(c.Request().Context(), uid, invID); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func RedeemInviteLinkHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := s.RedeemInviteLink(c.Request().Context(), uid, c.Param("token"))
+		if err != nil { return c.JSON(joinErrStatus(err), echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusOK, echo.Map{"status": "joined", "group_id": gid})
+	}
+}
@@ -1,13 +1,33 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
 )
 
+type decideJoinRequestReq struct {
+	Reason *string `json:"reason"`
+}
+
+type bulkFilterReq struct {
+	CreatedAfter  *time.Time `json:"created_after"`
+	CreatedBefore *time.Time `json:"created_before"`
+	EmailDomain   string     `json:"email_domain"`
+}
+
+type bulkDecideReq struct {
+	Action     string         `json:"action"`
+	RequestIDs []int64        `json:"request_ids"`
+	Filter     *bulkFilterReq `json:"filter"`
+	Reason     *string        `json:"reason"`
+}
+
 func ListJoinRequestsHandler(s *service.JoinRequestService) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		uid, ok := GetUserID(c)
@@ -23,6 +43,9 @@ func ListJoinRequestsHandler(s *service.JoinRequestService) echo.HandlerFunc {
 				"id": jr.ID,
 				"requester_id": jr.RequesterID,
 				"status": jr.Status,
+				"message": jr.Message,
+				"answers": jr.Answers,
+				"expires_at": jr.ExpiresAt,
 				"created_at": jr.CreatedAt,
 			})
 		}
@@ -38,9 +61,12 @@ func ApproveJoinRequestHandler(s *service.JoinRequestService) echo.HandlerFunc {
 		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
 		reqID, err := strconv.ParseInt(c.Param("req_id"), 10, 64)
 		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid request id"}) }
-		if err := s.Approve(c.Request().Context(), gid, uid, reqID); err != nil {
-			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
-		}
+		req := new(decideJoinRequestReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		err = s.Approve(c.Request().Context(), gid, uid, reqID, req.Reason)
+		if errors.Is(err, service.ErrJoinRequestNotFound) { return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()}) }
+		if errors.Is(err, service.ErrGroupArchived) { return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
 		return c.NoContent(http.StatusNoContent)
 	}
 }
@@ -53,9 +79,79 @@ func DeclineJoinRequestHandler(s *service.JoinRequestService) echo.HandlerFunc {
 		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
 		reqID, err := strconv.ParseInt(c.Param("req_id"), 10, 64)
 		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid request id"}) }
-		if err := s.Decline(c.Request().Context(), gid, uid, reqID); err != nil {
-			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		req := new(decideJoinRequestReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		err = s.Decline(c.Request().Context(), gid, uid, reqID, req.Reason)
+		if errors.Is(err, service.ErrJoinRequestNotFound) { return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func BulkDecideJoinRequestsHandler(s *service.JoinRequestService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(bulkDecideReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		if req.Action != "approve" && req.Action != "decline" { return c.JSON(http.StatusBadRequest, echo.Map{"error": "action must be approve or decline"}) }
+		if len(req.RequestIDs) == 0 && req.Filter == nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "request_ids or filter required"}) }
+		in := service.BulkDecisionInput{GroupID: gid, OwnerID: uid, Approve: req.Action == "approve", Reason: req.Reason, IDs: req.RequestIDs}
+		if req.Filter != nil && len(req.RequestIDs) == 0 {
+			in.Filter = store.JoinRequestFilter{CreatedAfter: req.Filter.CreatedAfter, CreatedBefore: req.Filter.CreatedBefore, EmailDomain: req.Filter.EmailDomain}
+		}
+		results, err := s.BulkDecide(c.Request().Context(), in)
+		if errors.Is(err, service.ErrGroupArchived) { return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(results))
+		summary := map[string]int{}
+		for _, r := range results {
+			out = append(out, echo.Map{"request_id": r.RequestID, "outcome": r.Outcome})
+			summary[r.Outcome]++
+		}
+		return c.JSON(http.StatusOK, echo.Map{"results": out, "summary": summary})
+	}
+}
+
+func ListMyJoinRequestsHandler(s *service.JoinRequestService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		limit := 50
+		if l := c.QueryParam("limit"); l != "" {
+			if n, err := strconv.Atoi(l); err == nil { limit = n }
 		}
+		page, err := s.ListMine(c.Request().Context(), uid, c.QueryParam("status"), c.QueryParam("cursor"), limit)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(page.Requests))
+		for _, jr := range page.Requests {
+			out = append(out, echo.Map{
+				"id": jr.ID,
+				"group_id": jr.GroupID,
+				"group_name": jr.GroupName,
+				"status": jr.Status,
+				"message": jr.Message,
+				"decision_reason": jr.DecisionReason,
+				"decided_at": jr.DecidedAt,
+				"expires_at": jr.ExpiresAt,
+				"created_at": jr.CreatedAt,
+			})
+		}
+		return c.JSON(http.StatusOK, echo.Map{"join_requests": out, "next_cursor": page.NextCursor})
+	}
+}
+
+func CancelJoinRequestHandler(s *service.JoinRequestService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		reqID, err := strconv.ParseInt(c.Param("req_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid request id"}) }
+		err = s.Cancel(c.Request().Context(), uid, reqID)
+		if errors.Is(err, service.ErrJoinRequestNotFound) { return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
 		return c.NoContent(http.StatusNoContent)
 	}
 }
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
+)
+
+type setRoleReq struct {
+	Role string `json:"role"`
+}
+
+type muteReq struct {
+	DurationSeconds int        `json:"duration_seconds"`
+	Until           *time.Time `json:"until"`
+	Reason          *string    `json:"reason"`
+}
+
+type memberResp struct {
+	UserID      int64      `json:"user_id"`
+	DisplayName string     `json:"display_name"`
+	Role        string     `json:"role"`
+	JoinedAt    time.Time  `json:"joined_at"`
+	MutedUntil  *time.Time `json:"muted_until,omitempty"`
+}
+
+// membersErrStatus maps errors from the member listing to a response
+// status; anything unexpected is a 500.
+func membersErrStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrGroupNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrMembersHidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrInvalidRole), errors.Is(err, store.ErrInvalidCursor):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func ListMembersHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		limit := 50
+		if l := c.QueryParam("limit"); l != "" {
+			if n, err := strconv.Atoi(l); err == nil { limit = n }
+		}
+		page, err := s.ListMembers(c.Request().Context(), service.ListMembersInput{
+			GroupID:     gid,
+			RequesterID: uid,
+			Role:        c.QueryParam("role"),
+			Query:       c.QueryParam("q"),
+			Cursor:      c.QueryParam("cursor"),
+			Limit:       limit,
+		})
+		if err != nil {
+			status := membersErrStatus(err)
+			if status == http.StatusInternalServerError { return c.JSON(status, echo.Map{"error": "internal error"}) }
+			return c.JSON(status, echo.Map{"error": err.Error()})
+		}
+		out := make([]memberResp, 0, len(page.Members))
+		for _, m := range page.Members {
+			out = append(out, memberResp{UserID: m.UserID, DisplayName: m.DisplayName, Role: m.Role, JoinedAt: m.JoinedAt, MutedUntil: m.MutedUntil})
+		}
+		return c.JSON(http.StatusOK, echo.Map{
+			"members":     out,
+			"next_cursor": page.NextCursor,
+			"counts":      page.Counts,
+			"total":       page.Total,
+		})
+	}
+}
+
+func SetMemberRoleHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		target, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"}) }
+		req := new(setRoleReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		if err := s.SetMemberRole(c.Request().Context(), gid, uid, target, req.Role); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// MuteMemberHandler mutes a member for duration_seconds or until a given
+// time, with an optional reason.
+func MuteMemberHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		target, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"}) }
+		req := new(muteReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		var until time.Time
+		switch {
+		case req.Until != nil:
+			until = *req.Until
+		case req.DurationSeconds > 0:
+			until = time.Now().Add(time.Duration(req.DurationSeconds) * time.Second)
+		default:
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "duration_seconds or until required"})
+		}
+		if err := s.MuteMember(c.Request().Context(), gid, uid, target, until, req.Reason); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func UnmuteMemberHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		target, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"}) }
+		if err := s.UnmuteMember(c.Request().Context(), gid, uid, target); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func MembershipTimelineHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		var target int64
+		if v := c.QueryParam("user_id"); v != "" {
+			if target, err = strconv.ParseInt(v, 10, 64); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid user id"}) }
+		}
+		limit := 50
+		if l := c.QueryParam("limit"); l != "" {
+			if n, err := strconv.Atoi(l); err == nil { limit = n }
+		}
+		page, err := s.MembershipTimeline(c.Request().Context(), gid, uid, target, c.QueryParam("cursor"), limit)
+		if errors.Is(err, store.ErrInvalidCursor) { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(page.Events))
+		for _, ev := range page.Events {
+			out = append(out, echo.Map{
+				"id": ev.ID,
+				"user_id": ev.UserID,
+				"actor_id": ev.ActorID,
+				"event": ev.Event,
+				"role": ev.Role,
+				"created_at": ev.CreatedAt,
+			})
+		}
+		return c.JSON(http.StatusOK, echo.Map{"events": out, "next_cursor": page.NextCursor})
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"secure-messaging-backend/internal/config"
+	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
+)
+
+func TestMembersErrStatus(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{service.ErrGroupNotFound, http.StatusNotFound},
+		{service.ErrMembersHidden, http.StatusForbidden},
+		{service.ErrInvalidRole, http.StatusBadRequest},
+		{fmt.Errorf("decode: %w", store.ErrInvalidCursor), http.StatusBadRequest},
+		{sql.ErrNoRows, http.StatusInternalServerError},
+		{errors.New("pq: connection refused"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		if got := membersErrStatus(tc.err); got != tc.want { t.Errorf("membersErrStatus(%v) = %d, want %d", tc.err, got, tc.want) }
+	}
+}
+
+// An unknown role filter is rejected before the group is looked up, so the
+// handler needs no database.
+func TestListMembersRejectsUnknownRole(t *testing.T) {
+	svc := service.NewGroupService(&config.Config{}, nil, nil, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/groups/1/members?role=boss", nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Set(ctxUserIDKey, int64(7))
+	if err := ListMembersHandler(svc)(c); err != nil { t.Fatal(err) }
+	if rec.Code != http.StatusBadRequest { t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest) }
+	if !strings.Contains(rec.Body.String(), service.ErrInvalidRole.Error()) { t.Errorf("body = %s", rec.Body.String()) }
+}
@@ -1,44 +1,104 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
 )
 
 type sendMessageReq struct {
 	Text string `json:"text"`
 }
 
+type markReadReq struct {
+	MessageID int64 `json:"message_id"`
+}
+
+type reviewMessageReq struct {
+	Reason *string `json:"reason"`
+}
+
 type messageResp struct {
 	ID        int64     `json:"id"`
+	ChannelID int64     `json:"channel_id"`
 	SenderID  int64     `json:"sender_id"`
 	Text      string    `json:"text"`
+	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+func toMessageResp(m *service.MessageDTO) messageResp {
+	return messageResp{ID: m.ID, ChannelID: m.ChannelID, SenderID: m.SenderID, Text: string(m.Plain), Status: m.Status, CreatedAt: m.CreatedAt}
+}
+
+// channelParam reads the channel from the path or the channel_id query
+// parameter; 0 selects the group's default channel.
+func channelParam(c echo.Context) (int64, error) {
+	v := c.Param("channel_id")
+	if v == "" { v = c.QueryParam("channel_id") }
+	if v == "" { return 0, nil }
+	return strconv.ParseInt(v, 10, 64)
+}
+
+// messageErrStatus maps message service errors to a response status.
+func messageErrStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrChannelNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrGroupArchived), errors.Is(err, service.ErrChannelArchived):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
 func SendMessageHandler(s *service.MessageService) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		uid, ok := GetUserID(c)
 		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
 		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		chID, err := channelParam(c)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid channel id"}) }
 		var req sendMessageReq
 		if err := c.Bind(&req); err != nil || req.Text == "" {
 			return c.JSON(http.StatusBadRequest, echo.Map{"error": "text required"})
 		}
 		msg, err := s.Send(c.Request().Context(), service.SendMessageInput{
-			GroupID:  gid,
-			SenderID: uid,
-			Plain:    []byte(req.Text),
+			GroupID:   gid,
+			ChannelID: chID,
+			SenderID:  uid,
+			Plain:     []byte(req.Text),
 		})
+		var muted *service.MutedError
+		if errors.As(err, &muted) {
+			return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error(), "code": "muted", "muted_until": muted.Until, "reason": muted.Reason})
+		}
+		var rules *service.RulesAcceptanceError
+		if errors.As(err, &rules) {
+			return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error(), "code": "rules_acceptance_required", "rules_version": rules.Version})
+		}
+		var limited *service.RateLimitedError
+		if errors.As(err, &limited) {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(limited.Seconds()))
+			return c.JSON(http.StatusTooManyRequests, echo.Map{"error": err.Error(), "code": "rate_limited", "retry_after": limited.Seconds()})
+		}
+		if errors.Is(err, service.ErrAutomodRejected) {
+			return c.JSON(http.StatusUnprocessableEntity, echo.Map{"error": err.Error(), "code": "automod_rejected"})
+		}
+		if errors.Is(err, service.ErrAnnouncementOnly) {
+			return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error(), "code": "announcement_only"})
+		}
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+			return c.JSON(messageErrStatus(err), echo.Map{"error": err.Error()})
 		}
-		return c.JSON(http.StatusCreated, messageResp{ID: msg.ID, SenderID: msg.SenderID, Text: string(msg.Plain), CreatedAt: msg.CreatedAt})
+		// A held message is stored but not yet visible to the group.
+		if msg.Status == store.MessageHeld { return c.JSON(http.StatusAccepted, toMessageResp(msg)) }
+		return c.JSON(http.StatusCreated, toMessageResp(msg))
 	}
 }
 
@@ -48,6 +108,8 @@ func ListMessagesHandler(s *service.MessageService) echo.HandlerFunc {
 		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
 		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
 		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		chID, err := channelParam(c)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid channel id"}) }
 		limit := 50
 		if l := c.QueryParam("limit"); l != "" {
 			if n, err := strconv.Atoi(l); err == nil { limit = n }
@@ -56,14 +118,73 @@ func ListMessagesHandler(s *service.MessageService) echo.HandlerFunc {
 		if b := c.QueryParam("before"); b != "" {
 			if t, err := time.Parse(time.RFC3339, b); err == nil { beforePtr = &t }
 		}
-		rows, err := s.List(c.Request().Context(), gid, uid, limit, beforePtr)
+		rows, err := s.List(c.Request().Context(), gid, chID, uid, limit, beforePtr)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+			return c.JSON(messageErrStatus(err), echo.Map{"error": err.Error()})
 		}
 		out := make([]messageResp, 0, len(rows))
-		for _, r := range rows {
-			out = append(out, messageResp{ID: r.ID, SenderID: r.SenderID, Text: string(r.Plain), CreatedAt: r.CreatedAt})
+		for i := range rows {
+			out = append(out, toMessageResp(&rows[i]))
 		}
 		return c.JSON(http.StatusOK, echo.Map{"messages": out})
 	}
 }
+
+func MarkReadHandler(s *service.MessageService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		var req markReadReq
+		if err := c.Bind(&req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		if err := s.MarkRead(c.Request().Context(), gid, uid, req.MessageID); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func HeldMessagesHandler(s *service.MessageService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		limit := 50
+		if l := c.QueryParam("limit"); l != "" {
+			if n, err := strconv.Atoi(l); err == nil { limit = n }
+		}
+		page, err := s.HeldMessages(c.Request().Context(), gid, uid, c.QueryParam("cursor"), limit)
+		if errors.Is(err, store.ErrInvalidCursor) { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error()}) }
+		out := make([]messageResp, 0, len(page.Messages))
+		for i := range page.Messages {
+			out = append(out, toMessageResp(&page.Messages[i]))
+		}
+		return c.JSON(http.StatusOK, echo.Map{"messages": out, "next_cursor": page.NextCursor})
+	}
+}
+
+func ApproveHeldMessageHandler(s *service.MessageService) echo.HandlerFunc { return reviewHeldMessage(s, true) }
+
+func RejectHeldMessageHandler(s *service.MessageService) echo.HandlerFunc { return reviewHeldMessage(s, false) }
+
+// reviewHeldMessage approves or rejects a held message, with an optional
+// reason.
+func reviewHeldMessage(s *service.MessageService, approve bool) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		mid, err := strconv.ParseInt(c.Param("message_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid message id"}) }
+		req := new(reviewMessageReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		if err := s.ReviewHeldMessage(c.Request().Context(), gid, uid, mid, approve, req.Reason); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
@@ -6,38 +6,107 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+	"secure-messaging-backend/internal/store"
 )
 
 const ctxUserIDKey = "user_id"
 
-func JWTMiddleware(secret string) echo.MiddlewareFunc {
+// JWTMiddleware requires a valid access token. Tokens of deactivated and
+// suspended accounts are refused, so closing an account ends its sessions
+// without waiting for expiry.
+func JWTMiddleware(secret string, users *store.UserStore) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			auth := c.Request().Header.Get("Authorization")
 			if len(auth) < 8 || auth[:7] != "Bearer " {
 				return c.JSON(http.StatusUnauthorized, echo.Map{"error": "missing bearer token"})
 			}
-			tokStr := auth[7:]
-			tok, err := jwt.Parse(tokStr, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, echo.ErrUnauthorized
-				}
-				return []byte(secret), nil
-			})
-			if err != nil || !tok.Valid {
-				return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid token"})
-			}
-			claims, ok := tok.Claims.(jwt.MapClaims)
-			if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid claims"}) }
-			sub, _ := claims["sub"].(string)
-			uid, err := strconv.ParseInt(sub, 10, 64)
-			if err != nil { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "invalid subject"}) }
+			uid, msg := parseAccessToken(secret, auth[7:])
+			if msg != "" { return c.JSON(http.StatusUnauthorized, echo.Map{"error": msg}) }
+			if msg := accountBlocked(c, users, uid); msg != "" { return c.JSON(http.StatusUnauthorized, echo.Map{"error": msg}) }
 			c.Set(ctxUserIDKey, uid)
 			return next(c)
 		}
 	}
 }
 
+// OptionalJWTMiddleware sets the user id when a valid bearer token of an
+// active account is present and lets anonymous requests through otherwise.
+func OptionalJWTMiddleware(secret string, users *store.UserStore) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth := c.Request().Header.Get("Authorization")
+			if len(auth) >= 8 && auth[:7] == "Bearer " {
+				if uid, msg := parseAccessToken(secret, auth[7:]); msg == "" && accountBlocked(c, users, uid) == "" { c.Set(ctxUserIDKey, uid) }
+			}
+			return next(c)
+		}
+	}
+}
+
+// HideSecretGroupsMiddleware answers 404 on routes with a group :id when the
+// group is secret and the caller is not a member, so secret groups can't be
+// discovered by probing ids. It runs after JWTMiddleware.
+func HideSecretGroupsMiddleware(groups *store.GroupStore) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+			if err != nil { return next(c) }
+			uid, _ := GetUserID(c)
+			hidden, err := groups.HiddenFrom(c.Request().Context(), gid, uid)
+			if err != nil { return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()}) }
+			if hidden { return c.JSON(http.StatusNotFound, echo.Map{"error": "group not found"}) }
+			return next(c)
+		}
+	}
+}
+
+// RequireAdminMiddleware limits a route group to platform admins. It runs
+// after JWTMiddleware and answers 404 so the admin surface isn't advertised.
+func RequireAdminMiddleware(users *store.UserStore) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			uid, _ := GetUserID(c)
+			u, err := users.GetUserByID(c.Request().Context(), uid)
+			if err != nil || !u.IsAdmin { return c.JSON(http.StatusNotFound, echo.Map{"error": "not found"}) }
+			return next(c)
+		}
+	}
+}
+
+// accountBlocked returns a non-empty error message for the client when the
+// account can no longer use its tokens.
+func accountBlocked(c echo.Context, users *store.UserStore, uid int64) string {
+	deactivated, suspended, err := users.AccountStatus(c.Request().Context(), uid)
+	switch {
+	case err != nil:
+		return "invalid token"
+	case deactivated:
+		return "account deactivated"
+	case suspended:
+		return "account suspended"
+	}
+	return ""
+}
+
+// parseAccessToken validates tokStr and returns its subject, or a non-empty
+// error message for the client.
+func parseAccessToken(secret, tokStr string) (int64, string) {
+	tok, err := jwt.Parse(tokStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, echo.ErrUnauthorized
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !tok.Valid { return 0, "invalid token" }
+	claims, ok := tok.Claims.(jwt.MapClaims)
+	if !ok { return 0, "invalid claims" }
+	sub, _ := claims["sub"].(string)
+	uid, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil { return 0, "invalid subject" }
+	return uid, ""
+}
+
 func GetUserID(c echo.Context) (int64, bool) {
 	v := c.Get(ctxUserIDKey)
 	if v == nil { return 0, false }
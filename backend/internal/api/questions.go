@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
+)
+
+type questionReq struct {
+	Prompt   string   `json:"prompt"`
+	Kind     string   `json:"kind"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
+
+type setQuestionsReq struct {
+	Questions []questionReq `json:"questions"`
+}
+
+func questionsResp(qs []store.Question) echo.Map {
+	out := make([]echo.Map, 0, len(qs))
+	for _, q := range qs {
+		out = append(out, echo.Map{
+			"id": q.ID,
+			"prompt": q.Prompt,
+			"kind": q.Kind,
+			"options": q.Options,
+			"required": q.Required,
+		})
+	}
+	return echo.Map{"questions": out}
+}
+
+func ListQuestionsHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		qs, err := s.ListQuestions(c.Request().Context(), gid)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusOK, questionsResp(qs))
+	}
+}
+
+func SetQuestionsHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(setQuestionsReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		in := make([]service.QuestionInput, 0, len(req.Questions))
+		for _, q := range req.Questions {
+			in = append(in, service.QuestionInput{Prompt: q.Prompt, Kind: q.Kind, Options: q.Options, Required: q.Required})
+		}
+		qs, err := s.SetQuestions(c.Request().Context(), gid, uid, in)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusOK, questionsResp(qs))
+	}
+}
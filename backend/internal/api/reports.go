@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
+)
+
+type createReportReq struct {
+	TargetType string `json:"target_type"`
+	MessageID  int64  `json:"message_id"`
+	UserID     int64  `json:"user_id"`
+	GroupID    int64  `json:"group_id"`
+	Category   string `json:"category"`
+	Comment    string `json:"comment"`
+}
+
+type resolveReportReq struct {
+	Action string  `json:"action"`
+	Note   *string `json:"note"`
+}
+
+func reportResp(r store.Report) echo.Map {
+	return echo.Map{
+		"id": r.ID,
+		"reporter_id": r.ReporterID,
+		"target_type": r.TargetType,
+		"message_id": r.MessageID,
+		"user_id": r.UserID,
+		"group_id": r.GroupID,
+		"category": r.Category,
+		"comment": r.Comment,
+		"status": r.Status,
+		"resolution": r.Resolution,
+		"resolution_note": r.ResolutionNote,
+		"resolved_by": r.ResolvedBy,
+		"resolved_at": r.ResolvedAt,
+		"created_at": r.CreatedAt,
+	}
+}
+
+func reportPageResp(c echo.Context, page *service.ReportPage) error {
+	out := make([]echo.Map, 0, len(page.Reports))
+	for _, r := range page.Reports {
+		out = append(out, reportResp(r))
+	}
+	return c.JSON(http.StatusOK, echo.Map{"reports": out, "next_cursor": page.NextCursor})
+}
+
+func queueLimit(c echo.Context) int {
+	limit := 50
+	if l := c.QueryParam("limit"); l != "" {
+		if n, err := strconv.Atoi(l); err == nil { limit = n }
+	}
+	return limit
+}
+
+func CreateReportHandler(s *service.ReportService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		req := new(createReportReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		r, err := s.Create(c.Request().Context(), service.ReportInput{
+			ReporterID: uid,
+			TargetType: req.TargetType,
+			MessageID:  req.MessageID,
+			UserID:     req.UserID,
+			GroupID:    req.GroupID,
+			Category:   req.Category,
+			Comment:    req.Comment,
+		})
+		switch {
+		case errors.Is(err, store.ErrDuplicateReport):
+			return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+		case errors.Is(err, service.ErrGroupNotFound):
+			return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+		case err != nil:
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.JSON(http.StatusCreated, reportResp(*r))
+	}
+}
+
+func GroupReportsHandler(s *service.ReportService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		page, err := s.GroupQueue(c.Request().Context(), gid, uid, c.QueryParam("status"), c.QueryParam("cursor"), queueLimit(c))
+		if errors.Is(err, store.ErrInvalidCursor) { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error()}) }
+		return reportPageResp(c, page)
+	}
+}
+
+func AdminReportsHandler(s *service.ReportService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		page, err := s.AdminQueue(c.Request().Context(), c.QueryParam("status"), c.QueryParam("target_type"), c.QueryParam("cursor"), queueLimit(c))
+		if errors.Is(err, store.ErrInvalidCursor) { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()}) }
+		return reportPageResp(c, page)
+	}
+}
+
+func ResolveReportHandler(s *service.ReportService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		rid, err := strconv.ParseInt(c.Param("report_id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid report id"}) }
+		req := new(resolveReportReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		err = s.Resolve(c.Request().Context(), rid, uid, req.Action, req.Note)
+		if errors.Is(err, service.ErrReportNotFound) { return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		return c.NoContent(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
+)
+
+type setRulesReq struct {
+	Body string `json:"body"`
+}
+
+type acceptRulesReq struct {
+	Version int `json:"version"`
+}
+
+func rulesResp(r *store.GroupRules, accepted int) echo.Map {
+	return echo.Map{
+		"version": r.Version,
+		"body": r.Body,
+		"created_by": r.CreatedBy,
+		"created_at": r.CreatedAt,
+		"accepted_version": accepted,
+		"accepted": r.Body == "" || accepted >= r.Version,
+	}
+}
+
+func GetRulesHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, _ := GetUserID(c)
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		version := 0
+		if v := c.QueryParam("version"); v != "" {
+			if version, err = strconv.Atoi(v); err != nil || version < 1 { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid version"}) }
+		}
+		r, accepted, err := s.Rules(c.Request().Context(), gid, uid, version)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		if r == nil { return c.JSON(http.StatusNotFound, echo.Map{"error": "group has no rules"}) }
+		return c.JSON(http.StatusOK, rulesResp(r, accepted))
+	}
+}
+
+func SetRulesHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(setRulesReq)
+		if err := c.Bind(req); err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid body"}) }
+		r, err := s.SetRules(c.Request().Context(), gid, uid, req.Body)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		return c.JSON(http.StatusOK, rulesResp(r, r.Version))
+	}
+}
+
+func AcceptRulesHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		gid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid group id"}) }
+		req := new(acceptRulesReq)
+		if err := c.Bind(req); err != nil || req.Version == 0 { return c.JSON(http.StatusBadRequest, echo.Map{"error": "version required"}) }
+		if err := s.AcceptRules(c.Request().Context(), gid, uid, req.Version); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+}
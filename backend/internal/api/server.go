@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -9,6 +10,8 @@ import (
 	"github.com/rs/zerolog"
 	"golang.org/x/time/rate"
 	"secure-messaging-backend/internal/config"
+	"secure-messaging-backend/internal/jobs"
+	"secure-messaging-backend/internal/notify"
 	"secure-messaging-backend/internal/service"
 	"secure-messaging-backend/internal/store"
 )
@@ -19,7 +22,9 @@ type ServerDeps struct {
 	DB  *sqlx.DB
 }
 
-func NewServer(cfg *config.Config, log zerolog.Logger, db *sqlx.DB) *echo.Echo {
+// NewServer builds the router and registers the services' background jobs on
+// runner; the caller starts the runner.
+func NewServer(cfg *config.Config, log zerolog.Logger, db *sqlx.DB, runner *jobs.Runner) *echo.Echo {
 	e := echo.New()
 	e.HideBanner = true
 	e.Use(middleware.Recover())
@@ -31,16 +36,27 @@ func NewServer(cfg *config.Config, log zerolog.Logger, db *sqlx.DB) *echo.Echo {
 	e.GET("/healthz", func(c echo.Context) error { return c.String(http.StatusOK, "ok") })
 
 	// Build stores/services
+	notifier := notify.NewLogNotifier(log)
+	uow := store.NewUnitOfWork(db)
 	userStore := store.NewUserStore(db)
-	authSvc := service.NewAuthService(cfg, userStore)
+	authSvc := service.NewAuthService(cfg, uow, userStore)
 	groupStore := store.NewGroupStore(db)
-	groupSvc := service.NewGroupService(groupStore, userStore, cfg.MasterKey)
-	joinSvc := service.NewJoinRequestService(groupStore)
+	groupSvc := service.NewGroupService(cfg, uow, groupStore, userStore, notifier)
+	joinSvc := service.NewJoinRequestService(uow, groupStore)
 	msgStore := store.NewMessageStore(db)
-	msgSvc := service.NewMessageService(cfg, groupStore, msgStore, log)
+	msgSvc := service.NewMessageService(cfg, uow, groupStore, msgStore, notifier, log)
+	adminSvc := service.NewAdminService(uow, userStore, groupStore, store.NewAdminStore(db), notifier)
+	reportSvc := service.NewReportService(uow, store.NewReportStore(db), groupStore, userStore, msgStore, groupSvc, notifier)
+
+	// Background jobs
+	runner.Add(jobs.Job{Name: "expire-join-requests", Interval: 10 * time.Minute, Run: joinSvc.ExpirePending})
+	runner.Add(jobs.Job{Name: "expire-ownership-transfers", Interval: 10 * time.Minute, Run: groupSvc.ExpireOwnershipTransfers})
+	runner.Add(jobs.Job{Name: "owner-succession", Interval: time.Hour, Run: groupSvc.SucceedAbsentOwners})
+	runner.Add(jobs.Job{Name: "purge-deleted-groups", Interval: time.Hour, Run: groupSvc.PurgeDeleted})
 
 	// API routes under /api/v1
 	v1 := e.Group("/api/v1")
+	requireAuth := JWTMiddleware(cfg.JWTAccessSecret, userStore)
 
 	// Auth
 	auth := v1.Group("/auth")
@@ -54,26 +70,124 @@ func NewServer(cfg *config.Config, log zerolog.Logger, db *sqlx.DB) *echo.Echo {
 	auth.POST("/login", LoginHandler(authSvc))
 	auth.POST("/refresh", RefreshHandler(authSvc))
 
-	// Groups: GET is public (lists public + owned when auth provided)
-	v1.GET("/groups", ListGroupsHandler(groupSvc))
+	// Groups: GET is public (membership status included when auth provided)
+	optAuth := OptionalJWTMiddleware(cfg.JWTAccessSecret, userStore)
+	v1.GET("/groups", ListGroupsHandler(groupSvc), optAuth)
+	v1.GET("/groups/search", SearchGroupsHandler(groupSvc), optAuth)
 	// Other group operations require auth
 	grp := v1.Group("/groups")
-	grp.Use(JWTMiddleware(cfg.JWTAccessSecret))
+	grp.Use(requireAuth)
+	grp.Use(HideSecretGroupsMiddleware(groupStore))
 	grp.POST("", CreateGroupHandler(groupSvc))
 	grp.POST("/:id/join", JoinGroupHandler(groupSvc))
 	grp.POST("/:id/leave", LeaveGroupHandler(groupSvc))
 	grp.POST("/:id/transfer-owner", TransferOwnerHandler(groupSvc))
+	grp.GET("/:id/transfer-owner", PendingTransferHandler(groupSvc))
+	grp.DELETE("/:id/transfer-owner", CancelTransferHandler(groupSvc))
+	grp.POST("/:id/transfer-owner/accept", AcceptTransferHandler(groupSvc))
+	grp.POST("/:id/transfer-owner/decline", DeclineTransferHandler(groupSvc))
+	grp.GET("/:id/transfer-owner/events", TransferHistoryHandler(groupSvc))
 	grp.DELETE("/:id", DeleteGroupHandler(groupSvc))
+	grp.POST("/:id/restore", RestoreGroupHandler(groupSvc))
+	grp.POST("/:id/archive", ArchiveGroupHandler(groupSvc))
+	grp.DELETE("/:id/archive", ArchiveGroupHandler(groupSvc))
 	grp.POST("/:id/banish", BanishHandler(groupSvc))
+	grp.DELETE("/:id/bans/:user_id", UnbanHandler(groupSvc))
+	grp.GET("/:id/appeals", ListAppealsHandler(groupSvc))
+	grp.POST("/:id/appeals/:appeal_id/grant", GrantAppealHandler(groupSvc))
+	grp.POST("/:id/appeals/:appeal_id/deny", DenyAppealHandler(groupSvc))
+	grp.PATCH("/:id/settings", UpdateSettingsHandler(groupSvc))
+	grp.POST("/:id/vouches", VouchHandler(groupSvc))
+	grp.DELETE("/:id/vouches/:user_id", RevokeVouchHandler(groupSvc))
+	grp.GET("/:id/waitlist/me", WaitlistPositionHandler(groupSvc))
+	grp.DELETE("/:id/waitlist/me", LeaveWaitlistHandler(groupSvc))
+
+	// Invitations and invite links
+	grp.GET("/:id/invites", ListInvitesHandler(groupSvc))
+	grp.POST("/:id/invites", InviteUserHandler(groupSvc))
+	grp.POST("/:id/invite-links", CreateInviteLinkHandler(groupSvc))
+	grp.DELETE("/:id/invites/:invite_id", RevokeInviteHandler(groupSvc))
+	v1.POST("/invites/:token/redeem", RedeemInviteLinkHandler(groupSvc), requireAuth)
+
+	// Members
+	grp.GET("/:id/members", ListMembersHandler(groupSvc))
+	grp.PUT("/:id/members/:user_id/role", SetMemberRoleHandler(groupSvc))
+	grp.PUT("/:id/members/:user_id/mute", MuteMemberHandler(groupSvc))
+	grp.DELETE("/:id/members/:user_id/mute", UnmuteMemberHandler(groupSvc))
+	grp.GET("/:id/membership-events", MembershipTimelineHandler(groupSvc))
+	grp.GET("/:id/audit-log", AuditLogHandler(groupSvc))
+	grp.GET("/:id/reports", GroupReportsHandler(reportSvc))
+
+	// Screening questions for join requests
+	grp.GET("/:id/questions", ListQuestionsHandler(groupSvc))
+	grp.PUT("/:id/questions", SetQuestionsHandler(groupSvc))
+
+	// Group rules, accepted by members before they post
+	grp.GET("/:id/rules", GetRulesHandler(groupSvc))
+	grp.PUT("/:id/rules", SetRulesHandler(groupSvc))
+	grp.POST("/:id/rules/accept", AcceptRulesHandler(groupSvc))
 
 	// Join Requests (owner only actions)
 	grp.GET("/:id/join-requests", ListJoinRequestsHandler(joinSvc))
 	grp.POST("/:id/join-requests/:req_id/approve", ApproveJoinRequestHandler(joinSvc))
 	grp.POST("/:id/join-requests/:req_id/decline", DeclineJoinRequestHandler(joinSvc))
+	grp.POST("/:id/join-requests/bulk", BulkDecideJoinRequestsHandler(joinSvc))
 
 	// Messaging
 	grp.POST("/:id/messages", SendMessageHandler(msgSvc))
 	grp.GET("/:id/messages", ListMessagesHandler(msgSvc))
+	grp.POST("/:id/messages/read", MarkReadHandler(msgSvc))
+	grp.GET("/:id/held-messages", HeldMessagesHandler(msgSvc))
+	grp.POST("/:id/held-messages/:message_id/approve", ApproveHeldMessageHandler(msgSvc))
+	grp.POST("/:id/held-messages/:message_id/reject", RejectHeldMessageHandler(msgSvc))
+	grp.GET("/:id/automod/rules", ListAutomodRulesHandler(groupSvc))
+	grp.POST("/:id/automod/rules", CreateAutomodRuleHandler(groupSvc))
+	grp.PATCH("/:id/automod/rules/:rule_id", UpdateAutomodRuleHandler(groupSvc))
+	grp.DELETE("/:id/automod/rules/:rule_id", DeleteAutomodRuleHandler(groupSvc))
+	grp.POST("/:id/automod/test", TestAutomodHandler(groupSvc))
+	grp.GET("/:id/automod/flags", FlaggedMessagesHandler(msgSvc))
+	grp.POST("/:id/channels/:channel_id/messages", SendMessageHandler(msgSvc))
+	grp.GET("/:id/channels/:channel_id/messages", ListMessagesHandler(msgSvc))
+
+	// Channels
+	grp.GET("/:id/channels", ListChannelsHandler(groupSvc))
+	grp.POST("/:id/channels", CreateChannelHandler(groupSvc))
+	grp.PUT("/:id/channels/order", ReorderChannelsHandler(groupSvc))
+	grp.PATCH("/:id/channels/:channel_id", RenameChannelHandler(groupSvc))
+	grp.POST("/:id/channels/:channel_id/archive", ArchiveChannelHandler(groupSvc))
+	grp.DELETE("/:id/channels/:channel_id/archive", ArchiveChannelHandler(groupSvc))
+	grp.POST("/:id/channels/:channel_id/members", AddChannelMemberHandler(groupSvc))
+	grp.DELETE("/:id/channels/:channel_id/members/:user_id", RemoveChannelMemberHandler(groupSvc))
+
+	// Abuse reports
+	v1.POST("/reports", CreateReportHandler(reportSvc), requireAuth)
+	v1.POST("/reports/:report_id/resolve", ResolveReportHandler(reportSvc), requireAuth)
+
+	// Platform administration
+	admin := v1.Group("/admin")
+	admin.Use(requireAuth)
+	admin.Use(RequireAdminMiddleware(userStore))
+	admin.GET("/reports", AdminReportsHandler(reportSvc))
+	admin.GET("/users", AdminSearchUsersHandler(adminSvc))
+	admin.POST("/users/:user_id/suspension", SuspendUserHandler(adminSvc))
+	admin.DELETE("/users/:user_id/suspension", UnsuspendUserHandler(adminSvc))
+	admin.DELETE("/groups/:id", AdminDeleteGroupHandler(adminSvc))
+	admin.POST("/groups/:id/transfer-owner", AdminTransferOwnerHandler(adminSvc))
+	admin.GET("/stats", AdminStatsHandler(adminSvc))
+	admin.GET("/audit-log", AdminAuditLogHandler(adminSvc))
+
+	// Current user
+	me := v1.Group("/users/me")
+	me.Use(requireAuth)
+	me.DELETE("", DeactivateAccountHandler(authSvc))
+	me.GET("/groups", ListMyGroupsHandler(groupSvc))
+	me.GET("/join-requests", ListMyJoinRequestsHandler(joinSvc))
+	me.DELETE("/join-requests/:req_id", CancelJoinRequestHandler(joinSvc))
+	me.GET("/invites", ListMyInvitesHandler(groupSvc))
+	me.POST("/invites/:invite_id/accept", AcceptInviteHandler(groupSvc))
+	me.DELETE("/invites/:invite_id", DeclineInviteHandler(groupSvc))
+	me.GET("/appeals", ListMyAppealsHandler(groupSvc))
+	me.POST("/bans/:group_id/appeal", AppealBanHandler(groupSvc))
 
 	// Swagger placeholder
 	e.GET("/swagger", func(c echo.Context) error {
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"secure-messaging-backend/internal/service"
+	"secure-messaging-backend/internal/store"
+)
+
+func ListMyGroupsHandler(s *service.GroupService) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		uid, ok := GetUserID(c)
+		if !ok { return c.JSON(http.StatusUnauthorized, echo.Map{"error": "unauthorized"}) }
+		limit := 50
+		if l := c.QueryParam("limit"); l != "" {
+			if n, err := strconv.Atoi(l); err == nil { limit = n }
+		}
+		page, err := s.ListMine(c.Request().Context(), uid, c.QueryParam("role"), c.QueryParam("cursor"), limit)
+		if errors.Is(err, store.ErrInvalidCursor) { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		if err != nil { return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()}) }
+		out := make([]echo.Map, 0, len(page.Groups))
+		for _, g := range page.Groups {
+			var last interface{}
+			if g.LastMessageID != nil {
+				last = echo.Map{"id": *g.LastMessageID, "sender_id": *g.LastMessageSenderID, "created_at": *g.LastMessageAt}
+			}
+			out = append(out, echo.Map{
+				"id": g.ID,
+				"name": g.Name,
+				"description": g.Description,
+				"tags": g.Tags,
+				"type": g.Type,
+				"owner_id": g.OwnerID,
+				"max_members": g.MaxMembers,
+				"member_count": g.MemberCount,
+				"role": g.Role,
+				"joined_at": g.JoinedAt,
+				"last_activity_at": g.LastActivityAt,
+				"archived": g.Archived(),
+				"unread_count": g.UnreadCount,
+				"last_message": last,
+			})
+		}
+		return c.JSON(http.StatusOK, echo.Map{"groups": out, "next_cursor": page.NextCursor})
+	}
+}
@@ -7,16 +7,23 @@ import (
 )
 
 type Config struct {
-	Env                 string `env:"APP_ENV" envDefault:"dev"`
-	HTTPPort            string `env:"HTTP_PORT" envDefault:"8080"`
-	DatabaseURL         string `env:"DATABASE_URL,required"`
-	JWTAccessSecret     string `env:"JWT_ACCESS_SECRET,required"`
-	JWTRefreshSecret    string `env:"JWT_REFRESH_SECRET,required"`
-	AccessTokenMinutes  int    `env:"ACCESS_TOKEN_MINUTES" envDefault:"15"`
-	RefreshTokenDays    int    `env:"REFRESH_TOKEN_DAYS" envDefault:"7"`
-	MasterKey           string `env:"MASTER_KEY,required"` // 32 bytes base64 or hex
-	RateLimitPerMinute  int    `env:"AUTH_RATE_LIMIT_PER_MIN" envDefault:"60"`
-	FirebaseCredentials string `env:"FIREBASE_CREDENTIALS_JSON"` // optional JSON string
+	Env                   string `env:"APP_ENV" envDefault:"dev"`
+	HTTPPort              string `env:"HTTP_PORT" envDefault:"8080"`
+	DatabaseURL           string `env:"DATABASE_URL,required"`
+	JWTAccessSecret       string `env:"JWT_ACCESS_SECRET,required"`
+	JWTRefreshSecret      string `env:"JWT_REFRESH_SECRET,required"`
+	AccessTokenMinutes    int    `env:"ACCESS_TOKEN_MINUTES" envDefault:"15"`
+	RefreshTokenDays      int    `env:"REFRESH_TOKEN_DAYS" envDefault:"7"`
+	MasterKey             string `env:"MASTER_KEY,required"` // 32 bytes base64 or hex
+	RateLimitPerMinute    int    `env:"AUTH_RATE_LIMIT_PER_MIN" envDefault:"60"`
+	FirebaseCredentials   string `env:"FIREBASE_CREDENTIALS_JSON"` // optional JSON string
+	JoinRequestTTLDays    int    `env:"JOIN_REQUEST_TTL_DAYS" envDefault:"30"`
+	OwnerTransferTTLHours int    `env:"OWNER_TRANSFER_TTL_HOURS" envDefault:"72"`
+	GroupRestoreDays      int    `env:"GROUP_RESTORE_DAYS" envDefault:"30"`
+	InviteTTLDays         int    `env:"INVITE_TTL_DAYS" envDefault:"7"`
+	MessageBurstLimit     int    `env:"MESSAGE_BURST_LIMIT" envDefault:"10"`
+	MessageBurstSeconds   int    `env:"MESSAGE_BURST_SECONDS" envDefault:"10"`
+	BanAppealCooldownDays int    `env:"BAN_APPEAL_COOLDOWN_DAYS" envDefault:"30"`
 }
 
 func Load() (*Config, error) {
@@ -24,5 +31,13 @@ func Load() (*Config, error) {
 	if err := env.Parse(cfg); err != nil {
 		return nil, fmt.Errorf("parse env: %w", err)
 	}
+	if cfg.MessageBurstLimit < 0 {
+		return nil, fmt.Errorf("MESSAGE_BURST_LIMIT must not be negative")
+	}
+	// A zero window would restart on every send and quietly turn the burst
+	// limit off; MESSAGE_BURST_LIMIT=0 is how to do that.
+	if cfg.MessageBurstLimit > 0 && cfg.MessageBurstSeconds <= 0 {
+		return nil, fmt.Errorf("MESSAGE_BURST_SECONDS must be positive when MESSAGE_BURST_LIMIT is set")
+	}
 	return cfg, nil
 }
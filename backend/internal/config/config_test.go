@@ -0,0 +1,34 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadValidatesBurstWindow(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/app")
+	t.Setenv("JWT_ACCESS_SECRET", "access")
+	t.Setenv("JWT_REFRESH_SECRET", "refresh")
+	t.Setenv("MASTER_KEY", "01234567890123456789012345678901")
+	cases := []struct {
+		limit, seconds string
+		wantErr        string
+	}{
+		{"10", "10", ""},
+		{"0", "0", ""},
+		{"10", "0", "MESSAGE_BURST_SECONDS"},
+		{"10", "-5", "MESSAGE_BURST_SECONDS"},
+		{"-1", "10", "MESSAGE_BURST_LIMIT"},
+	}
+	for _, tc := range cases {
+		t.Setenv("MESSAGE_BURST_LIMIT", tc.limit)
+		t.Setenv("MESSAGE_BURST_SECONDS", tc.seconds)
+		_, err := Load()
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("limit %s, window %s: unexpected error %v", tc.limit, tc.seconds, err)
+		case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+			t.Errorf("limit %s, window %s: err = %v, want mention of %s", tc.limit, tc.seconds, err, tc.wantErr)
+		}
+	}
+}
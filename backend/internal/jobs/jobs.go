@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Job is a periodic background task. Run must be safe to execute on several
+// replicas at once; jobs here are written as idempotent set-based updates.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Runner runs registered jobs on their intervals.
+type Runner struct {
+	log  zerolog.Logger
+	jobs []Job
+}
+
+func NewRunner(log zerolog.Logger) *Runner { return &Runner{log: log} }
+
+func (r *Runner) Add(j Job) { r.jobs = append(r.jobs, j) }
+
+// Start launches one goroutine per job; they stop when ctx is cancelled.
+func (r *Runner) Start(ctx context.Context) {
+	for _, j := range r.jobs {
+		go r.loop(ctx, j)
+	}
+}
+
+func (r *Runner) loop(ctx context.Context, j Job) {
+	t := time.NewTicker(j.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			start := time.Now()
+			if err := j.Run(ctx); err != nil {
+				r.log.Error().Err(err).Str("job", j.Name).Msg("job failed")
+				continue
+			}
+			r.log.Debug().Str("job", j.Name).Dur("took", time.Since(start)).Msg("job done")
+		}
+	}
+}
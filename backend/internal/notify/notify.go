@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// Payload is a push notification. Data carries machine-readable fields for
+// the client; Title and Body are for display.
+type Payload struct {
+	Type  string
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// Notifier delivers notifications to users' devices.
+type Notifier interface {
+	SendToUsers(ctx context.Context, userIDs []int64, p Payload) error
+}
+
+// LogNotifier simulates delivery by logging each notification.
+type LogNotifier struct{ log zerolog.Logger }
+
+func NewLogNotifier(log zerolog.Logger) *LogNotifier { return &LogNotifier{log: log} }
+
+func (n *LogNotifier) SendToUsers(ctx context.Context, userIDs []int64, p Payload) error {
+	if len(userIDs) == 0 { return nil }
+	ev := n.log.Info().Ints64("user_ids", userIDs).Str("type", p.Type).Str("title", p.Title)
+	for k, v := range p.Data { ev = ev.Str(k, v) }
+	ev.Msg("Notification sent")
+	return nil
+}
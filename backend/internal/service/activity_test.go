@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGroupActivityIsCoarse(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	users := e.createUsers(t, 1)
+	g := e.createGroup(t, users[0], "open", 10, false)
+	if _, err := e.db.ExecContext(ctx, `UPDATE groups SET last_activity_at = now() - interval '1 hour' WHERE id=$1`, g.ID); err != nil { t.Fatal(err) }
+	activity := func() time.Time {
+		var at time.Time
+		if err := e.db.GetContext(ctx, &at, `SELECT last_activity_at FROM groups WHERE id=$1`, g.ID); err != nil { t.Fatal(err) }
+		return at
+	}
+
+	// A stale group is touched by the next message; messages within the
+	// following minute leave it alone.
+	first, err := e.messages.Send(ctx, SendMessageInput{GroupID: g.ID, SenderID: users[0], Plain: []byte("one")})
+	if err != nil { t.Fatal(err) }
+	if got := activity(); !got.Equal(first.CreatedAt) { t.Errorf("last_activity_at = %v, want %v", got, first.CreatedAt) }
+	if _, err := e.messages.Send(ctx, SendMessageInput{GroupID: g.ID, SenderID: users[0], Plain: []byte("two")}); err != nil { t.Fatal(err) }
+	if got := activity(); !got.Equal(first.CreatedAt) { t.Errorf("last_activity_at = %v after a second message, want %v", got, first.CreatedAt) }
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"secure-messaging-backend/internal/notify"
+	"secure-messaging-backend/internal/store"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+// activeUserWindow is how recently a user must have been seen to count as
+// active in the system stats.
+const activeUserWindow = 30 * 24 * time.Hour
+
+// AdminService carries the platform-wide powers of users flagged is_admin.
+// Callers are authorized by RequireAdminMiddleware; every change is recorded
+// in the admin audit log.
+type AdminService struct {
+	uow      *store.UnitOfWork
+	users    *store.UserStore
+	groups   *store.GroupStore
+	admin    *store.AdminStore
+	notifier notify.Notifier
+}
+
+func NewAdminService(uow *store.UnitOfWork, users *store.UserStore, groups *store.GroupStore, admin *store.AdminStore, notifier notify.Notifier) *AdminService {
+	return &AdminService{uow: uow, users: users, groups: groups, admin: admin, notifier: notifier}
+}
+
+type UserPage struct {
+	Users      []store.User
+	NextCursor string
+}
+
+// SearchUsers pages accounts whose email or display name contains query.
+func (s *AdminService) SearchUsers(ctx context.Context, query string, suspended bool, cursor string, limit int) (*UserPage, error) {
+	after, err := store.DecodeCursor(cursor)
+	if err != nil { return nil, err }
+	if limit <= 0 || limit > 100 { limit = 50 }
+	rows, err := s.users.SearchUsers(ctx, store.UserSearch{Query: strings.TrimSpace(query), Suspended: suspended, After: after, Limit: limit + 1})
+	if err != nil { return nil, err }
+	page := &UserPage{Users: rows}
+	if len(rows) > limit {
+		page.Users = rows[:limit]
+		page.NextCursor = store.Cursor{ID: page.Users[limit-1].ID}.Encode()
+	}
+	return page, nil
+}
+
+// Suspend blocks the account from signing in and revokes its sessions.
+func (s *AdminService) Suspend(ctx context.Context, adminID, userID int64, reason *string) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		return suspendTx(ctx, tx, adminID, userID, reason, nil)
+	})
+}
+
+// suspendTx suspends userID on behalf of adminID and records it; details
+// says what prompted it, if anything.
+func suspendTx(ctx context.Context, tx *store.Tx, adminID, userID int64, reason *string, details any) error {
+	if userID == adminID { return errors.New("cannot suspend yourself") }
+	u, err := tx.Users.GetUserByID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) { return ErrUserNotFound }
+	if err != nil { return err }
+	if u.IsAdmin { return errors.New("cannot suspend a platform admin") }
+	if u.SuspendedAt != nil { return errors.New("account is already suspended") }
+	if err := tx.Users.Suspend(ctx, userID, reason); err != nil { return err }
+	return tx.Admin.RecordAudit(ctx, store.AdminAuditRecord{ActorID: adminID, Action: store.AdminUserSuspended, TargetUserID: userID, Reason: reason, Details: details})
+}
+
+func (s *AdminService) Unsuspend(ctx context.Context, adminID, userID int64, reason *string) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		u, err := tx.Users.GetUserByID(ctx, userID)
+		if errors.Is(err, sql.ErrNoRows) { return ErrUserNotFound }
+		if err != nil { return err }
+		if u.SuspendedAt == nil { return errors.New("account is not suspended") }
+		if err := tx.Users.Unsuspend(ctx, userID); err != nil { return err }
+		return tx.Admin.RecordAudit(ctx, store.AdminAuditRecord{
+			ActorID: adminID, Action: store.AdminUserUnsuspended, TargetUserID: userID, Reason: reason,
+			Details: map[string]any{"suspended_at": u.SuspendedAt, "suspension_reason": u.SuspensionReason},
+		})
+	})
+}
+
+// DeleteGroup purges the group at once, skipping the owner's restore
+// window. Groups already deleted by their owner are purged early.
+func (s *AdminService) DeleteGroup(ctx context.Context, adminID, groupID int64, reason *string) error {
+	var g *store.Group
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		var err error
+		g, err = tx.Groups.LockGroup(ctx, groupID)
+		if errors.Is(err, sql.ErrNoRows) {
+			g, err = tx.Groups.LockDeletedGroup(ctx, groupID)
+			if errors.Is(err, sql.ErrNoRows) { return ErrGroupNotFound }
+		} else if err == nil {
+			if err = tx.Groups.DeleteGroup(ctx, groupID); err == nil {
+				now := time.Now()
+				g.DeletedAt = &now
+			}
+		}
+		if err != nil { return err }
+		p, err := tx.Groups.PurgeGroup(ctx, g)
+		if err != nil { return err }
+		return tx.Admin.RecordAudit(ctx, store.AdminAuditRecord{
+			ActorID: adminID, Action: store.AdminGroupDeleted, TargetUserID: g.OwnerID, TargetGroupID: groupID, Reason: reason,
+			Details: map[string]any{"name": g.Name, "member_count": p.MemberCount, "message_count": p.MessageCount},
+		})
+	})
+	if err != nil { return err }
+	_ = s.notifier.SendToUsers(ctx, []int64{g.OwnerID}, notify.Payload{
+		Type:  "group_removed",
+		Title: "Group removed",
+		Body:  g.Name + " was removed by a platform administrator.",
+		Data:  map[string]string{"group_id": strconv.FormatInt(groupID, 10)},
+	})
+	return nil
+}
+
+// TransferOwnership makes a member the owner without the usual offer and
+// acceptance; any pending offer is cancelled. The previous owner stays on
+// as an admin.
+func (s *AdminService) TransferOwnership(ctx context.Context, adminID, groupID, newOwner int64, reason *string) error {
+	var g *store.Group
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		var err error
+		g, err = tx.Groups.LockGroup(ctx, groupID)
+		if errors.Is(err, sql.ErrNoRows) { return ErrGroupNotFound }
+		if err != nil { return err }
+		if newOwner == g.OwnerID { return errors.New("already the owner") }
+		isMember, err := tx.Groups.IsMember(ctx, groupID, newOwner)
+		if err != nil { return err }
+		if !isMember { return errors.New("new owner must be a member") }
+		prev, err := tx.Groups.CancelPendingOwnershipTransfer(ctx, groupID)
+		if err != nil { return err }
+		if prev != nil {
+			if err := tx.Groups.RecordOwnershipTransferEvent(ctx, prev, adminID, store.TransferCancelled); err != nil { return err }
+			if err := recordTransferAudit(ctx, tx, prev, adminID, store.AuditOwnershipCancelled); err != nil { return err }
+		}
+		if err := tx.Groups.TransferOwner(ctx, groupID, newOwner); err != nil { return err }
+		if err := tx.Groups.RecordAudit(ctx, store.AuditRecord{
+			GroupID: groupID, ActorID: adminID, Action: store.AuditOwnershipForced, TargetUserID: newOwner, Reason: reason,
+			Before: map[string]int64{"owner_id": g.OwnerID}, After: map[string]int64{"owner_id": newOwner},
+		}); err != nil { return err }
+		if err := recordRoleChanges(ctx, tx, groupID, adminID, map[int64]string{g.OwnerID: store.RoleAdmin, newOwner: store.RoleOwner}); err != nil { return err }
+		return tx.Admin.RecordAudit(ctx, store.AdminAuditRecord{
+			ActorID: adminID, Action: store.AdminOwnershipTransfer, TargetUserID: newOwner, TargetGroupID: groupID, Reason: reason,
+			Details: map[string]int64{"previous_owner_id": g.OwnerID},
+		})
+	})
+	if err != nil { return err }
+	data := map[string]string{"group_id": strconv.FormatInt(groupID, 10)}
+	_ = s.notifier.SendToUsers(ctx, []int64{newOwner}, notify.Payload{
+		Type: "ownership_assigned", Title: "You are now the owner", Body: "A platform administrator made you the owner of " + g.Name + ".", Data: data,
+	})
+	_ = s.notifier.SendToUsers(ctx, []int64{g.OwnerID}, notify.Payload{
+		Type: "ownership_reassigned", Title: "Ownership reassigned", Body: "A platform administrator reassigned ownership of " + g.Name + ".", Data: data,
+	})
+	return nil
+}
+
+func (s *AdminService) Stats(ctx context.Context) (*store.SystemStats, error) {
+	return s.admin.Stats(ctx, time.Now().Add(-activeUserWindow))
+}
+
+type AdminAuditPage struct {
+	Entries    []store.AdminAuditEntry
+	NextCursor string
+}
+
+// AuditLog pages the admin audit log, newest first.
+func (s *AdminService) AuditLog(ctx context.Context, f store.AdminAuditFilter, cursor string, limit int) (*AdminAuditPage, error) {
+	after, err := store.DecodeCursor(cursor)
+	if err != nil { return nil, err }
+	if limit <= 0 || limit > 100 { limit = 50 }
+	rows, err := s.admin.ListAudit(ctx, f, after, limit+1)
+	if err != nil { return nil, err }
+	page := &AdminAuditPage{Entries: rows}
+	if len(rows) > limit {
+		page.Entries = rows[:limit]
+		page.NextCursor = store.Cursor{ID: page.Entries[limit-1].ID}.Encode()
+	}
+	return page, nil
+}
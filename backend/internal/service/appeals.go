@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"secure-messaging-backend/internal/notify"
+	"secure-messaging-backend/internal/store"
+)
+
+var ErrAppealNotFound = errors.New("appeal not found")
+
+const maxAppealLength = 2000
+
+// AppealCooldownError is returned by AppealBan while a denied appeal's
+// cooldown is running.
+type AppealCooldownError struct {
+	Until time.Time
+}
+
+func (e *AppealCooldownError) Error() string {
+	return "you can appeal again after " + e.Until.UTC().Format(time.RFC3339)
+}
+
+// Seconds is the time left rounded up to whole seconds, as sent in
+// Retry-After.
+func (e *AppealCooldownError) Seconds() int {
+	return int((time.Until(e.Until) + time.Second - 1) / time.Second)
+}
+
+func (s *GroupService) appealCooldown() time.Duration {
+	return time.Duration(s.cfg.BanAppealCooldownDays) * 24 * time.Hour
+}
+
+// AppealBan files the user's appeal against their ban from the group. Only
+// one appeal may be open at a time, and a new one can follow a denial only
+// after the cooldown. The group's owner and admins are notified.
+func (s *GroupService) AppealBan(ctx context.Context, groupID, userID int64, message string) (*store.BanAppeal, error) {
+	message = strings.TrimSpace(message)
+	if message == "" || len(message) > maxAppealLength { return nil, fmt.Errorf("message must be 1-%d characters", maxAppealLength) }
+	// The ban row stays locked until the appeal is stored, so a concurrent
+	// Unban either sees the appeal and grants it or has already lifted the ban.
+	var a *store.BanAppeal
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		if err := tx.Groups.LockBan(ctx, groupID, userID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) { return errors.New("you are not banned from this group") }
+			return err
+		}
+		last, err := tx.Groups.LastDeniedAppeal(ctx, groupID, userID)
+		if err != nil { return err }
+		if last != nil {
+			if until := last.Add(s.appealCooldown()); time.Now().Before(until) { return &AppealCooldownError{Until: until} }
+		}
+		a, err = tx.Groups.CreateAppeal(ctx, groupID, userID, message)
+		return err
+	})
+	if err != nil { return nil, err }
+	if mods, err := s.groups.ModeratorIDs(ctx, groupID); err == nil {
+		_ = s.notifier.SendToUsers(ctx, mods, notify.Payload{
+			Type:  "ban_appeal",
+			Title: "New ban appeal",
+			Body:  "A banned user has appealed their ban.",
+			Data:  map[string]string{"group_id": strconv.FormatInt(groupID, 10), "appeal_id": strconv.FormatInt(a.ID, 10)},
+		})
+	}
+	return a, nil
+}
+
+// MyAppeals returns the user's appeals across groups, newest first.
+func (s *GroupService) MyAppeals(ctx context.Context, userID int64) ([]store.BanAppeal, error) {
+	return s.groups.ListUserAppeals(ctx, userID)
+}
+
+type AppealPage struct {
+	Appeals    []store.BanAppeal
+	NextCursor string
+}
+
+// ListAppeals pages the group's appeals oldest first for owners and admins;
+// status defaults to open.
+func (s *GroupService) ListAppeals(ctx context.Context, groupID, moderatorID int64, status, cursor string, limit int) (*AppealPage, error) {
+	if err := s.requireModerator(ctx, groupID, moderatorID, "review appeals"); err != nil { return nil, err }
+	after, err := store.DecodeCursor(cursor)
+	if err != nil { return nil, err }
+	if status == "" { status = store.AppealOpen }
+	if limit <= 0 || limit > 100 { limit = 50 }
+	rows, err := s.groups.ListAppeals(ctx, groupID, status, after, limit+1)
+	if err != nil { return nil, err }
+	page := &AppealPage{Appeals: rows}
+	if len(rows) > limit {
+		page.Appeals = rows[:limit]
+		page.NextCursor = store.Cursor{ID: page.Appeals[limit-1].ID}.Encode()
+	}
+	return page, nil
+}
+
+// GrantAppeal lifts the ban, as Unban does; owner only.
+func (s *GroupService) GrantAppeal(ctx context.Context, groupID, actorID, appealID int64, reason *string) error {
+	return s.decideAppeal(ctx, groupID, actorID, appealID, store.AppealGranted, reason)
+}
+
+// DenyAppeal closes the appeal with a reason; owner or admins. The user may
+// appeal again after the cooldown.
+func (s *GroupService) DenyAppeal(ctx context.Context, groupID, actorID, appealID int64, reason *string) error {
+	if reason == nil || strings.TrimSpace(*reason) == "" { return errors.New("reason required") }
+	return s.decideAppeal(ctx, groupID, actorID, appealID, store.AppealDenied, reason)
+}
+
+func (s *GroupService) decideAppeal(ctx context.Context, groupID, actorID, appealID int64, status string, reason *string) error {
+	if reason != nil && len(*reason) > 500 { return errors.New("reason too long") }
+	var g *store.Group
+	var a *store.BanAppeal
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		var err error
+		g, err = tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		role, err := tx.Groups.GetMemberRole(ctx, groupID, actorID)
+		if err != nil { return err }
+		if !isModerator(role) { return errors.New("only owner or admins can review appeals") }
+		if status == store.AppealGranted && g.OwnerID != actorID { return errors.New("only owner can lift bans") }
+		a, err = tx.Groups.DecideAppeal(ctx, groupID, appealID, actorID, status, reason)
+		if err != nil { return err }
+		if a == nil { return ErrAppealNotFound }
+		if status == store.AppealDenied {
+			return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: actorID, Action: store.AuditAppealDenied, TargetUserID: a.UserID, TargetID: a.ID, Reason: reason})
+		}
+		if err := tx.Groups.RemoveBan(ctx, groupID, a.UserID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) { return errors.New("user is not banned") }
+			return err
+		}
+		if err := tx.Groups.RecordMembershipEvent(ctx, groupID, a.UserID, actorID, store.EventUnbanned, nil); err != nil { return err }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: actorID, Action: store.AuditMemberUnbanned, TargetUserID: a.UserID, TargetID: a.ID, Reason: reason})
+	})
+	if err != nil { return err }
+	p := notify.Payload{
+		Type:  "ban_appeal_denied",
+		Title: "Appeal denied",
+		Body:  "Your appeal against your ban from " + g.Name + " was denied.",
+		Data:  map[string]string{"group_id": strconv.FormatInt(groupID, 10), "appeal_id": strconv.FormatInt(a.ID, 10)},
+	}
+	if status == store.AppealGranted {
+		p.Type, p.Title, p.Body = "ban_appeal_granted", "Appeal granted", "Your ban from "+g.Name+" has been lifted."
+	}
+	if reason != nil { p.Data["reason"] = *reason }
+	_ = s.notifier.SendToUsers(ctx, []int64{a.UserID}, p)
+	return nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUnbanGrantsRacingAppeal(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	const rounds = 20
+	users := e.createUsers(t, 1+rounds)
+	owner := users[0]
+	g := e.createGroup(t, owner, "open", 100, false)
+	for _, u := range users[1:] {
+		if _, err := e.groups.Join(ctx, JoinInput{GroupID: g.ID, UserID: u}); err != nil { t.Fatal(err) }
+		if err := e.groups.Banish(ctx, g.ID, owner, u, nil); err != nil { t.Fatal(err) }
+	}
+	// Whichever side wins, an unbanned user must not be left with an open
+	// appeal.
+	for _, u := range users[1:] {
+		race(2, func(i int) {
+			if i == 0 {
+				_, _ = e.groups.AppealBan(ctx, g.ID, u, "please")
+				return
+			}
+			if err := e.groups.Unban(ctx, g.ID, owner, u); err != nil { t.Errorf("unban %d: %v", u, err) }
+		})
+	}
+	var open int
+	if err := e.db.GetContext(ctx, &open, `SELECT COUNT(*) FROM ban_appeals WHERE group_id=$1 AND status='open'`, g.ID); err != nil { t.Fatal(err) }
+	if open != 0 { t.Errorf("open appeals after unban = %d, want 0", open) }
+}
+
+func TestAppealRequiresBan(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	users := e.createUsers(t, 2)
+	g := e.createGroup(t, users[0], "open", 10, false)
+	if _, err := e.groups.AppealBan(ctx, g.ID, users[1], "please"); err == nil || err.Error() != "you are not banned from this group" { t.Fatalf("appeal without ban: err = %v", err) }
+}
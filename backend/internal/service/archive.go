@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"secure-messaging-backend/internal/store"
+)
+
+// ErrGroupArchived rejects writes (messages, joins, approvals) to an archived
+// group. Members can still read its history.
+var ErrGroupArchived = errors.New("group is archived and read-only")
+
+// SetArchived archives or unarchives the group; owners and admins only.
+func (s *GroupService) SetArchived(ctx context.Context, groupID, userID int64, archived bool) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		g, err := tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		role, err := tx.Groups.GetMemberRole(ctx, groupID, userID)
+		if err != nil { return err }
+		if !isModerator(role) { return errors.New("only owner or admins can archive") }
+		if g.Archived() == archived { return nil }
+		if err := tx.Groups.SetArchived(ctx, groupID, userID, archived); err != nil { return err }
+		action := store.AuditGroupArchived
+		if !archived { action = store.AuditGroupUnarchived }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: userID, Action: action})
+	})
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"secure-messaging-backend/internal/store"
+)
+
+type AuditLogInput struct {
+	GroupID      int64
+	RequesterID  int64
+	Action       string
+	ActorID      int64
+	TargetUserID int64
+	Since        *time.Time
+	Until        *time.Time
+	Cursor       string
+	Limit        int
+}
+
+type AuditLogPage struct {
+	Entries    []store.AuditEntry
+	NextCursor string
+}
+
+// AuditLog pages the group's moderation audit log, newest first, for owners
+// and admins.
+func (s *GroupService) AuditLog(ctx context.Context, in AuditLogInput) (*AuditLogPage, error) {
+	if err := s.requireModerator(ctx, in.GroupID, in.RequesterID, "view the audit log"); err != nil { return nil, err }
+	after, err := store.DecodeCursor(in.Cursor)
+	if err != nil { return nil, err }
+	if in.Since != nil && in.Until != nil && !in.Since.Before(*in.Until) { return nil, errors.New("since must be before until") }
+	if in.Limit <= 0 || in.Limit > 100 { in.Limit = 50 }
+	f := store.AuditFilter{Action: in.Action, ActorID: in.ActorID, TargetUserID: in.TargetUserID, Since: in.Since, Until: in.Until}
+	rows, err := s.groups.ListAudit(ctx, in.GroupID, f, after, in.Limit+1)
+	if err != nil { return nil, err }
+	page := &AuditLogPage{Entries: rows}
+	if len(rows) > in.Limit {
+		page.Entries = rows[:in.Limit]
+		page.NextCursor = store.Cursor{ID: page.Entries[in.Limit-1].ID}.Encode()
+	}
+	return page, nil
+}
+
+// settingsChanges returns the settings that differ between before and after,
+// keyed by column name, for the audit log.
+func settingsChanges(before, after store.GroupSettings) (map[string]any, map[string]any) {
+	b, a := map[string]any{}, map[string]any{}
+	bv, av := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := 0; i < bv.NumField(); i++ {
+		if reflect.DeepEqual(bv.Field(i).Interface(), av.Field(i).Interface()) { continue }
+		name := bv.Type().Field(i).Tag.Get("db")
+		b[name] = bv.Field(i).Interface()
+		a[name] = av.Field(i).Interface()
+	}
+	return b, a
+}
+
+func roleChange(role string) map[string]string { return map[string]string{"role": role} }
+
+func questionsAudit(qs []store.Question) []map[string]any {
+	out := make([]map[string]any, 0, len(qs))
+	for _, q := range qs {
+		out = append(out, map[string]any{"prompt": q.Prompt, "kind": q.Kind, "options": q.Options, "required": q.Required})
+	}
+	return out
+}
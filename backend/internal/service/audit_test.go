@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"secure-messaging-backend/internal/store"
+)
+
+func TestAuditLogIsAppendOnly(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	users := e.createUsers(t, 2)
+	g := e.createGroup(t, users[0], "open", 10, false)
+	if err := e.store.RecordAudit(ctx, store.AuditRecord{GroupID: g.ID, ActorID: users[1], Action: store.AuditChannelCreated}); err != nil { t.Fatal(err) }
+
+	for _, stmt := range []string{
+		`UPDATE audit_log SET reason='edited' WHERE group_id=$1`,
+		`DELETE FROM audit_log WHERE group_id=$1`,
+	} {
+		_, err := e.db.ExecContext(ctx, stmt, g.ID)
+		if err == nil || !strings.Contains(err.Error(), "append-only") { t.Errorf("%s: err = %v, want append-only refusal", stmt, err) }
+	}
+	// Hard-deleting the actor leaves the entry as it was.
+	if _, err := e.db.ExecContext(ctx, `DELETE FROM users WHERE id=$1`, users[1]); err != nil { t.Fatalf("delete actor: %v", err) }
+
+	// Purging the group cascades its entries away.
+	if _, err := e.db.ExecContext(ctx, `DELETE FROM groups WHERE id=$1`, g.ID); err != nil { t.Fatalf("purge: %v", err) }
+	var n int
+	if err := e.db.GetContext(ctx, &n, `SELECT COUNT(*) FROM audit_log WHERE group_id=$1`, g.ID); err != nil { t.Fatal(err) }
+	if n != 0 { t.Errorf("audit entries after purge = %d, want 0", n) }
+}
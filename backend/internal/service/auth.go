@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,11 +17,16 @@ import (
 
 type AuthService struct {
 	cfg       *config.Config
+	uow       *store.UnitOfWork
 	users     *store.UserStore
 }
 
-func NewAuthService(cfg *config.Config, users *store.UserStore) *AuthService {
-	return &AuthService{cfg: cfg, users: users}
+// ErrAccountSuspended rejects sign-in to an account suspended by an
+// administrator.
+var ErrAccountSuspended = errors.New("account suspended")
+
+func NewAuthService(cfg *config.Config, uow *store.UnitOfWork, users *store.UserStore) *AuthService {
+	return &AuthService{cfg: cfg, uow: uow, users: users}
 }
 
 type TokenPair struct {
@@ -28,13 +34,14 @@ type TokenPair struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
-func (s *AuthService) Register(ctx context.Context, email, password string) (*store.User, error) {
+func (s *AuthService) Register(ctx context.Context, email, displayName, password string) (*store.User, error) {
 	if len(password) < 8 {
 		return nil, errors.New("password too short")
 	}
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil { return nil, err }
-	u, err := s.users.CreateUser(ctx, email, string(hash))
+	if displayName == "" { displayName = strings.SplitN(email, "@", 2)[0] }
+	u, err := s.users.CreateUser(ctx, email, displayName, string(hash))
 	return u, err
 }
 
@@ -44,6 +51,8 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (*store
 	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
 		return nil, nil, errors.New("invalid credentials")
 	}
+	if u.DeactivatedAt != nil { return nil, nil, errors.New("account deactivated") }
+	if u.SuspendedAt != nil { return nil, nil, ErrAccountSuspended }
 	pair, err := s.issueTokens(ctx, u.ID)
 	if err != nil { return nil, nil, err }
 	return u, pair, nil
@@ -56,10 +65,32 @@ func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*TokenP
 		_ = s.users.DeleteRefreshToken(ctx, refreshToken)
 		return nil, errors.New("refresh token expired")
 	}
-	return s.issueTokens(ctx, rt.UserID)
+	// The user row lock orders this against Suspend and Deactivate, so a
+	// token issued here is either refused or revoked along with the rest.
+	var pair *TokenPair
+	err = s.uow.Do(ctx, func(tx *store.Tx) error {
+		u, err := tx.Users.LockUser(ctx, rt.UserID)
+		if err != nil { return err }
+		if u.DeactivatedAt != nil { return errors.New("account deactivated") }
+		if u.SuspendedAt != nil { return ErrAccountSuspended }
+		pair, err = s.issueTokensWith(ctx, tx.Users, u.ID)
+		return err
+	})
+	return pair, err
+}
+
+// Deactivate closes the caller's account. Groups they own pass to a
+// successor on the next succession run.
+func (s *AuthService) Deactivate(ctx context.Context, userID int64) error {
+	return s.users.Deactivate(ctx, userID)
 }
 
 func (s *AuthService) issueTokens(ctx context.Context, userID int64) (*TokenPair, error) {
+	return s.issueTokensWith(ctx, s.users, userID)
+}
+
+func (s *AuthService) issueTokensWith(ctx context.Context, users *store.UserStore, userID int64) (*TokenPair, error) {
+	if err := users.TouchLastSeen(ctx, userID); err != nil { return nil, err }
 	accessExp := time.Now().Add(time.Duration(s.cfg.AccessTokenMinutes) * time.Minute)
 	accessClaims := jwt.MapClaims{
 		"sub": fmt.Sprintf("%d", userID),
@@ -75,7 +106,7 @@ func (s *AuthService) issueTokens(ctx context.Context, userID int64) (*TokenPair
 	if _, err := rand.Read(b); err != nil { return nil, err }
 	refreshStr := base64.RawURLEncoding.EncodeToString(b)
 	refreshExp := time.Now().Add(time.Duration(s.cfg.RefreshTokenDays) * 24 * time.Hour)
-	if err := s.users.CreateRefreshToken(ctx, userID, refreshStr, refreshExp); err != nil {
+	if err := users.CreateRefreshToken(ctx, userID, refreshStr, refreshExp); err != nil {
 		return nil, err
 	}
 	return &TokenPair{AccessToken: accessStr, RefreshToken: refreshStr}, nil
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"secure-messaging-backend/internal/notify"
+	"secure-messaging-backend/internal/store"
+)
+
+// ErrAutomodRejected is returned by Send when a reject rule matches.
+var ErrAutomodRejected = errors.New("message blocked by the group's automod rules")
+
+const (
+	maxAutomodRules   = 100
+	maxAutomodPattern = 200
+)
+
+var (
+	linkHostRE = regexp.MustCompile(`(?i)\b(?:https?://|www\.)([a-z0-9.-]+)`)
+	mentionRE  = regexp.MustCompile(`(?:^|\s)@\w+`)
+)
+
+// automodCacheSize bounds the compiled patterns kept per service.
+const automodCacheSize = 1000
+
+// automodCache keeps compiled keyword and regex rules so sends do not
+// recompile them, evicting the least recently used once full. Entries are
+// keyed on id and pattern, so a rule that is gone or changed simply stops
+// being looked up and ages out.
+type automodCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // of *automodEntry, most recently used first
+	items map[automodKey]*list.Element
+}
+
+type automodKey struct {
+	id      int64
+	pattern string
+}
+
+type automodEntry struct {
+	key automodKey
+	re  *regexp.Regexp
+}
+
+func newAutomodCache(size int) *automodCache {
+	return &automodCache{size: size, order: list.New(), items: map[automodKey]*list.Element{}}
+}
+
+// regexp returns the compiled matcher for a keyword or regex rule, or nil if
+// the pattern does not compile. A nil cache, and unsaved drafts (id 0),
+// compile every time.
+func (c *automodCache) regexp(r store.AutomodRule) *regexp.Regexp {
+	key := automodKey{id: r.ID, pattern: r.Pattern}
+	if c != nil && r.ID != 0 {
+		c.mu.Lock()
+		if el, ok := c.items[key]; ok {
+			c.order.MoveToFront(el)
+			c.mu.Unlock()
+			return el.Value.(*automodEntry).re
+		}
+		c.mu.Unlock()
+	}
+	src := r.Pattern
+	if r.Kind == store.AutomodKeyword { src = `(?i)(?:^|\W)` + regexp.QuoteMeta(r.Pattern) + `(?:$|\W)` }
+	re, err := regexp.Compile(src)
+	if err != nil { return nil }
+	if c != nil && r.ID != 0 {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if _, ok := c.items[key]; !ok {
+			c.items[key] = c.order.PushFront(&automodEntry{key: key, re: re})
+			for c.order.Len() > c.size {
+				oldest := c.order.Back()
+				c.order.Remove(oldest)
+				delete(c.items, oldest.Value.(*automodEntry).key)
+			}
+		}
+	}
+	return re
+}
+
+type AutomodRuleInput struct {
+	Kind      string
+	Pattern   string
+	Threshold int
+	Action    string
+}
+
+// normalize validates the input and returns it as a rule, with keywords
+// and domains lowercased.
+func (in AutomodRuleInput) normalize() (store.AutomodRule, error) {
+	r := store.AutomodRule{Kind: in.Kind, Pattern: strings.TrimSpace(in.Pattern), Action: in.Action, Enabled: true}
+	if !validAutomodAction(r.Action) { return r, errors.New("action must be reject, hold or flag") }
+	if len(r.Pattern) > maxAutomodPattern { return r, fmt.Errorf("pattern must be at most %d characters", maxAutomodPattern) }
+	switch r.Kind {
+	case store.AutomodKeyword:
+		r.Pattern = strings.ToLower(r.Pattern)
+		if r.Pattern == "" { return r, errors.New("keyword required") }
+	case store.AutomodRegex:
+		if r.Pattern == "" { return r, errors.New("regex required") }
+		if _, err := regexp.Compile(r.Pattern); err != nil { return r, fmt.Errorf("invalid regex: %w", err) }
+	case store.AutomodLinkDomain:
+		r.Pattern = strings.TrimPrefix(strings.ToLower(r.Pattern), "www.")
+		if !strings.Contains(r.Pattern, ".") || strings.ContainsAny(r.Pattern, "/:@ ") { return r, errors.New("invalid link domain") }
+	case store.AutomodMentions:
+		r.Pattern = ""
+		if in.Threshold < 1 { return r, errors.New("threshold must be at least 1") }
+		r.Threshold = in.Threshold
+	default:
+		return r, errors.New("kind must be keyword, regex, link_domain or mentions")
+	}
+	return r, nil
+}
+
+func validAutomodAction(a string) bool {
+	return a == store.AutomodReject || a == store.AutomodHold || a == store.AutomodFlag
+}
+
+func automodStrength(action string) int {
+	switch action {
+	case store.AutomodReject:
+		return 3
+	case store.AutomodHold:
+		return 2
+	case store.AutomodFlag:
+		return 1
+	}
+	return 0
+}
+
+// automodMatches reports whether rule r fires on text, compiling patterns
+// through cache.
+func automodMatches(cache *automodCache, r store.AutomodRule, text string) bool {
+	switch r.Kind {
+	case store.AutomodKeyword, store.AutomodRegex:
+		re := cache.regexp(r)
+		return re != nil && re.MatchString(text)
+	case store.AutomodLinkDomain:
+		for _, m := range linkHostRE.FindAllStringSubmatch(text, -1) {
+			host := strings.TrimSuffix(strings.ToLower(m[1]), ".")
+			if host == r.Pattern || strings.HasSuffix(host, "."+r.Pattern) { return true }
+		}
+	case store.AutomodMentions:
+		return len(mentionRE.FindAllString(text, -1)) > r.Threshold
+	}
+	return false
+}
+
+// AutomodVerdict is the outcome of evaluating rules against a message: the
+// strongest action among the rules that fired, or "" if none did.
+type AutomodVerdict struct {
+	Action  string
+	RuleIDs []int64
+	Matched []store.AutomodRule
+}
+
+func evaluateAutomod(cache *automodCache, rules []store.AutomodRule, text string) AutomodVerdict {
+	var v AutomodVerdict
+	for _, r := range rules {
+		if !automodMatches(cache, r, text) { continue }
+		v.Matched = append(v.Matched, r)
+		if r.ID != 0 { v.RuleIDs = append(v.RuleIDs, r.ID) }
+		if automodStrength(r.Action) > automodStrength(v.Action) { v.Action = r.Action }
+	}
+	return v
+}
+
+func (s *GroupService) ListAutomodRules(ctx context.Context, groupID, userID int64) ([]store.AutomodRule, error) {
+	if err := s.requireModerator(ctx, groupID, userID, "manage automod rules"); err != nil { return nil, err }
+	return s.groups.ListAutomodRules(ctx, groupID, false)
+}
+
+func (s *GroupService) CreateAutomodRule(ctx context.Context, groupID, userID int64, in AutomodRuleInput) (*store.AutomodRule, error) {
+	if err := s.requireModerator(ctx, groupID, userID, "manage automod rules"); err != nil { return nil, err }
+	r, err := in.normalize()
+	if err != nil { return nil, err }
+	r.GroupID = groupID
+	var out *store.AutomodRule
+	err = s.uow.Do(ctx, func(tx *store.Tx) error {
+		if _, err := tx.Groups.LockGroup(ctx, groupID); err != nil { return err }
+		n, err := tx.Groups.CountAutomodRules(ctx, groupID)
+		if err != nil { return err }
+		if n >= maxAutomodRules { return fmt.Errorf("at most %d automod rules per group", maxAutomodRules) }
+		out, err = tx.Groups.CreateAutomodRule(ctx, r, userID)
+		if err != nil { return err }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: userID, Action: store.AuditAutomodRuleCreated, TargetID: out.ID, After: automodAudit(out)})
+	})
+	if err != nil { return nil, err }
+	return out, nil
+}
+
+// UpdateAutomodRule changes a rule's action or enables/disables it; nil
+// fields are left unchanged.
+func (s *GroupService) UpdateAutomodRule(ctx context.Context, groupID, userID, ruleID int64, action *string, enabled *bool) (*store.AutomodRule, error) {
+	if err := s.requireModerator(ctx, groupID, userID, "manage automod rules"); err != nil { return nil, err }
+	if action != nil && !validAutomodAction(*action) { return nil, errors.New("action must be reject, hold or flag") }
+	var r *store.AutomodRule
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		if _, err := tx.Groups.LockGroup(ctx, groupID); err != nil { return err }
+		var err error
+		r, err = tx.Groups.GetAutomodRule(ctx, groupID, ruleID)
+		if err != nil { return err }
+		if r == nil { return errors.New("automod rule not found") }
+		before := automodAudit(r)
+		if action != nil { r.Action = *action }
+		if enabled != nil { r.Enabled = *enabled }
+		if err := tx.Groups.UpdateAutomodRule(ctx, r.ID, r.Action, r.Enabled); err != nil { return err }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: userID, Action: store.AuditAutomodRuleUpdated, TargetID: r.ID, Before: before, After: automodAudit(r)})
+	})
+	if err != nil { return nil, err }
+	return r, nil
+}
+
+func (s *GroupService) DeleteAutomodRule(ctx context.Context, groupID, userID, ruleID int64) error {
+	if err := s.requireModerator(ctx, groupID, userID, "manage automod rules"); err != nil { return err }
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		if _, err := tx.Groups.LockGroup(ctx, groupID); err != nil { return err }
+		r, err := tx.Groups.GetAutomodRule(ctx, groupID, ruleID)
+		if err != nil { return err }
+		if r == nil { return errors.New("automod rule not found") }
+		if err := tx.Groups.DeleteAutomodRule(ctx, r.ID); err != nil { return err }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: userID, Action: store.AuditAutomodRuleDeleted, TargetID: r.ID, Before: automodAudit(r)})
+	})
+}
+
+// TestAutomod evaluates text against the group's enabled rules, or against
+// draft alone when given, without posting anything or counting hits.
+func (s *GroupService) TestAutomod(ctx context.Context, groupID, userID int64, text string, draft *AutomodRuleInput) (*AutomodVerdict, error) {
+	if err := s.requireModerator(ctx, groupID, userID, "manage automod rules"); err != nil { return nil, err }
+	var rules []store.AutomodRule
+	if draft != nil {
+		r, err := draft.normalize()
+		if err != nil { return nil, err }
+		rules = []store.AutomodRule{r}
+	} else {
+		var err error
+		rules, err = s.groups.ListAutomodRules(ctx, groupID, true)
+		if err != nil { return nil, err }
+	}
+	v := evaluateAutomod(nil, rules, text)
+	return &v, nil
+}
+
+func automodAudit(r *store.AutomodRule) map[string]any {
+	return map[string]any{"kind": r.Kind, "pattern": r.Pattern, "threshold": r.Threshold, "action": r.Action, "enabled": r.Enabled}
+}
+
+// runAutomod evaluates the group's enabled rules against a message being
+// sent. Send counts the hits of v.RuleIDs once the outcome is final.
+func (s *MessageService) runAutomod(ctx context.Context, groupID int64, text string) (AutomodVerdict, error) {
+	rules, err := s.groups.ListAutomodRules(ctx, groupID, true)
+	if err != nil || len(rules) == 0 { return AutomodVerdict{}, err }
+	return evaluateAutomod(s.automod, rules, text), nil
+}
+
+// flagMessage records a flag on m and alerts the group's moderators. The
+// message is already posted, so failures are logged rather than returned.
+func (s *MessageService) flagMessage(ctx context.Context, g *store.Group, m *store.Message, ruleIDs []int64) {
+	if err := s.groups.FlagMessage(ctx, g.ID, m.ID, ruleIDs); err != nil {
+		s.log.Error().Err(err).Int64("message_id", m.ID).Msg("automod flag failed")
+		return
+	}
+	mods, err := s.groups.ModeratorIDs(ctx, g.ID)
+	if err != nil {
+		s.log.Error().Err(err).Int64("group_id", g.ID).Msg("automod flag: list moderators failed")
+		return
+	}
+	_ = s.notifier.SendToUsers(ctx, mods, notify.Payload{
+		Type:  "automod_flag",
+		Title: "Message flagged",
+		Body:  "A message in " + g.Name + " matched an automod rule.",
+		Data:  map[string]string{"group_id": strconv.FormatInt(g.ID, 10), "message_id": strconv.FormatInt(m.ID, 10)},
+	})
+}
+
+type FlaggedMessageDTO struct {
+	MessageDTO
+	RuleIDs   []int64
+	FlaggedAt time.Time
+}
+
+type FlaggedMessagePage struct {
+	Messages   []FlaggedMessageDTO
+	NextCursor string
+}
+
+// FlaggedMessages pages messages automod flagged, newest first, for owners
+// and admins.
+func (s *MessageService) FlaggedMessages(ctx context.Context, groupID, moderatorID int64, cursor string, limit int) (*FlaggedMessagePage, error) {
+	if err := s.requireModerator(ctx, groupID, moderatorID); err != nil { return nil, err }
+	after, err := store.DecodeCursor(cursor)
+	if err != nil { return nil, err }
+	if limit <= 0 || limit > 100 { limit = 50 }
+	g, err := s.groups.GetGroup(ctx, groupID)
+	if err != nil { return nil, err }
+	rows, err := s.groups.ListFlaggedMessages(ctx, groupID, after, limit+1)
+	if err != nil { return nil, err }
+	page := &FlaggedMessagePage{}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		page.NextCursor = store.Cursor{ID: rows[limit-1].ID}.Encode()
+	}
+	msgs := make([]store.Message, 0, len(rows))
+	for _, r := range rows { msgs = append(msgs, r.Message) }
+	dtos, err := s.decrypt(g, msgs)
+	if err != nil { return nil, err }
+	for i, d := range dtos {
+		page.Messages = append(page.Messages, FlaggedMessageDTO{MessageDTO: d, RuleIDs: rows[i].RuleIDs, FlaggedAt: rows[i].FlaggedAt})
+	}
+	return page, nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"secure-messaging-backend/internal/store"
+)
+
+func TestAutomodCacheIsBounded(t *testing.T) {
+	c := newAutomodCache(2)
+	rules := []store.AutomodRule{
+		{ID: 1, Kind: store.AutomodKeyword, Pattern: "spam"},
+		{ID: 2, Kind: store.AutomodRegex, Pattern: `free\s+money`},
+		{ID: 3, Kind: store.AutomodKeyword, Pattern: "scam"},
+	}
+	for _, r := range rules {
+		if c.regexp(r) == nil { t.Fatalf("rule %d did not compile", r.ID) }
+	}
+	if len(c.items) != 2 || c.order.Len() != 2 { t.Fatalf("cache holds %d/%d entries, want 2", len(c.items), c.order.Len()) }
+	if _, ok := c.items[automodKey{id: 1, pattern: "spam"}]; ok { t.Error("least recently used rule was not evicted") }
+
+	// A lookup with another pattern under the same id never returns the old
+	// matcher.
+	if !automodMatches(c, store.AutomodRule{ID: 3, Kind: store.AutomodKeyword, Pattern: "fraud"}, "this is fraud") { t.Error("changed pattern did not match") }
+	if automodMatches(c, store.AutomodRule{ID: 3, Kind: store.AutomodKeyword, Pattern: "fraud"}, "a scam") { t.Error("stale pattern matched") }
+
+	// Drafts and a nil cache compile without caching.
+	draft := store.AutomodRule{Kind: store.AutomodKeyword, Pattern: "draft"}
+	if !automodMatches(c, draft, "a draft rule") || !automodMatches(nil, draft, "a draft rule") { t.Error("draft did not match") }
+	if _, ok := c.items[automodKey{pattern: "draft"}]; ok { t.Error("draft was cached") }
+}
+
+func TestAutomodHitsCountOnlyStoredMessages(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	users := e.createUsers(t, 2)
+	owner, member := users[0], users[1]
+	g := e.createGroup(t, owner, "open", 10, false)
+	if _, err := e.groups.Join(ctx, JoinInput{GroupID: g.ID, UserID: member}); err != nil { t.Fatal(err) }
+	slow := 60
+	if _, err := e.groups.UpdateSettings(ctx, g.ID, owner, SettingsPatch{SlowModeSeconds: &slow}); err != nil { t.Fatal(err) }
+	flag, err := e.groups.CreateAutomodRule(ctx, g.ID, owner, AutomodRuleInput{Kind: store.AutomodKeyword, Pattern: "spam", Action: store.AutomodFlag})
+	if err != nil { t.Fatal(err) }
+	reject, err := e.groups.CreateAutomodRule(ctx, g.ID, owner, AutomodRuleInput{Kind: store.AutomodKeyword, Pattern: "forbidden", Action: store.AutomodReject})
+	if err != nil { t.Fatal(err) }
+
+	if _, err := e.messages.Send(ctx, SendMessageInput{GroupID: g.ID, SenderID: member, Plain: []byte("spam one")}); err != nil { t.Fatal(err) }
+	var limited *RateLimitedError
+	if _, err := e.messages.Send(ctx, SendMessageInput{GroupID: g.ID, SenderID: member, Plain: []byte("spam two")}); !errors.As(err, &limited) { t.Fatalf("second send: err = %v, want rate limited", err) }
+	if _, err := e.messages.Send(ctx, SendMessageInput{GroupID: g.ID, SenderID: member, Plain: []byte("forbidden")}); !errors.Is(err, ErrAutomodRejected) { t.Fatalf("rejected send: err = %v", err) }
+
+	for _, want := range []struct {
+		id   int64
+		hits int64
+	}{{flag.ID, 1}, {reject.ID, 1}} {
+		r, err := e.store.GetAutomodRule(ctx, g.ID, want.id)
+		if err != nil { t.Fatal(err) }
+		if r.HitCount != want.hits { t.Errorf("rule %d hit_count = %d, want %d", want.id, r.HitCount, want.hits) }
+	}
+}
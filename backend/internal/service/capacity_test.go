@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"secure-messaging-backend/internal/store"
+)
+
+// These tests race concurrent joins, approvals and waitlist admissions
+// against a real Postgres; see newTestEnv.
+
+// waitlistUsers returns the queued user ids in admission order.
+func (e *testEnv) waitlistUsers(t *testing.T, groupID int64) []int64 {
+	t.Helper()
+	var ids []int64
+	if err := e.db.Select(&ids, `SELECT user_id FROM group_waitlist WHERE group_id=$1 ORDER BY id`, groupID); err != nil { t.Fatal(err) }
+	return ids
+}
+
+// watchCapacity samples the member count until stop is called and fails the
+// test if it is ever seen above capacity.
+func (e *testEnv) watchCapacity(t *testing.T, groupID int64, capacity int) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			n, err := e.store.CountMembers(context.Background(), groupID)
+			if err == nil && n > capacity { t.Errorf("member count %d exceeds max_members %d", n, capacity) }
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	return func() { close(done); <-finished }
+}
+
+// race runs fn(i) for i in [0, n) on n goroutines released together.
+func race(n int, fn func(i int)) {
+	var start, wg sync.WaitGroup
+	start.Add(1)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start.Wait()
+			fn(i)
+		}(i)
+	}
+	start.Done()
+	wg.Wait()
+}
+
+func TestConcurrentJoinRespectsCapacity(t *testing.T) {
+	e := newTestEnv(t)
+	const capacity, n = 5, 20
+	users := e.createUsers(t, n+1)
+	g := e.createGroup(t, users[0], "open", capacity, false)
+	stop := e.watchCapacity(t, g.ID, capacity)
+	var joined, full atomic.Int32
+	race(n, func(i int) {
+		status, err := e.groups.Join(context.Background(), JoinInput{GroupID: g.ID, UserID: users[i+1]})
+		switch {
+		case err == nil && status == "joined":
+			joined.Add(1)
+		case err != nil && err.Error() == "group full":
+			full.Add(1)
+		default:
+			t.Errorf("join %d: status %q, err %v", i, status, err)
+		}
+	})
+	stop()
+	if got := int(joined.Load()); got != capacity-1 { t.Errorf("joined = %d, want %d", got, capacity-1) }
+	if got := int(full.Load()); got != n-(capacity-1) { t.Errorf("group full = %d, want %d", got, n-(capacity-1)) }
+	if got := e.memberCount(t, g.ID); got != capacity { t.Errorf("members = %d, want %d", got, capacity) }
+}
+
+func TestConcurrentApproveRespectsCapacity(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	const capacity, n = 4, 12
+	users := e.createUsers(t, n+1)
+	owner := users[0]
+	g := e.createGroup(t, owner, "private", capacity, false)
+	reqIDs := make([]int64, n)
+	for i := range reqIDs {
+		status, err := e.groups.Join(ctx, JoinInput{GroupID: g.ID, UserID: users[i+1]})
+		if err != nil { t.Fatal(err) }
+		id, err := strconv.ParseInt(strings.TrimPrefix(status, "join_requested:"), 10, 64)
+		if err != nil { t.Fatalf("join status %q", status) }
+		reqIDs[i] = id
+	}
+	stop := e.watchCapacity(t, g.ID, capacity)
+	// Every request is approved individually while two bulk approvals sweep
+	// the whole queue.
+	race(n+2, func(i int) {
+		if i < n {
+			_ = e.requests.Approve(ctx, g.ID, owner, reqIDs[i], nil)
+			return
+		}
+		if _, err := e.requests.BulkDecide(ctx, BulkDecisionInput{GroupID: g.ID, OwnerID: owner, Approve: true, IDs: reqIDs}); err != nil { t.Errorf("bulk approve: %v", err) }
+	})
+	stop()
+	if got := e.memberCount(t, g.ID); got != capacity { t.Errorf("members = %d, want %d", got, capacity) }
+	var approved int
+	if err := e.db.Get(&approved, `SELECT COUNT(*) FROM join_requests WHERE group_id=$1 AND status=$2`, g.ID, store.JoinApproved); err != nil { t.Fatal(err) }
+	if approved != capacity-1 { t.Errorf("approved requests = %d, want %d", approved, capacity-1) }
+}
+
+func TestConcurrentWaitlistKeepsOrder(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	const capacity, queued, leaving, late = 3, 10, 2, 5
+	users := e.createUsers(t, 1+leaving+queued+late)
+	g := e.createGroup(t, users[0], "open", capacity, true)
+	members := users[1 : 1+leaving]
+	waiting := users[1+leaving : 1+leaving+queued]
+	latecomers := users[1+leaving+queued:]
+	for _, u := range members {
+		if status, err := e.groups.Join(ctx, JoinInput{GroupID: g.ID, UserID: u}); err != nil || status != "joined" { t.Fatalf("join: %q %v", status, err) }
+	}
+
+	// Racing joins into a full group each get a distinct queue position.
+	positions := make([]int, queued)
+	race(queued, func(i int) {
+		status, err := e.groups.Join(ctx, JoinInput{GroupID: g.ID, UserID: waiting[i]})
+		if err != nil { t.Errorf("join %d: %v", i, err); return }
+		pos, err := strconv.Atoi(strings.TrimPrefix(status, "waitlisted:"))
+		if err != nil { t.Errorf("join %d: status %q", i, status); return }
+		positions[i] = pos
+	})
+	order := e.waitlistUsers(t, g.ID)
+	if len(order) != queued { t.Fatalf("waitlist length = %d, want %d", len(order), queued) }
+	for i, u := range waiting {
+		if positions[i] < 1 || positions[i] > queued || order[positions[i]-1] != u { t.Errorf("user %d reported position %d, queue is %v", u, positions[i], order) }
+	}
+
+	// Members leave while latecomers try to join: the freed seats go to the
+	// head of the queue and latecomers line up behind it.
+	stop := e.watchCapacity(t, g.ID, capacity)
+	race(leaving+late, func(i int) {
+		if i < leaving {
+			if err := e.groups.Leave(ctx, g.ID, members[i]); err != nil { t.Errorf("leave %d: %v", i, err) }
+			return
+		}
+		if _, err := e.groups.Join(ctx, JoinInput{GroupID: g.ID, UserID: latecomers[i-leaving]}); err != nil { t.Errorf("late join %d: %v", i, err) }
+	})
+	stop()
+	if got := e.memberCount(t, g.ID); got != capacity { t.Errorf("members = %d, want %d", got, capacity) }
+	for _, u := range order[:leaving] {
+		if ok, err := e.store.IsMember(ctx, g.ID, u); err != nil { t.Fatal(err) } else if !ok { t.Errorf("head of queue user %d was not admitted", u) }
+	}
+	after := e.waitlistUsers(t, g.ID)
+	if len(after) != queued-leaving+late { t.Fatalf("waitlist length = %d, want %d", len(after), queued-leaving+late) }
+	for i, u := range order[leaving:] {
+		if after[i] != u { t.Fatalf("waitlist order changed: was %v, now %v", order, after) }
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"secure-messaging-backend/internal/store"
+)
+
+const maxChannelName = 50
+
+var (
+	ErrChannelNotFound = errors.New("channel not found")
+	ErrChannelArchived = errors.New("channel is archived")
+)
+
+func normalizeChannelName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" { return "", errors.New("channel name required") }
+	if len(name) > maxChannelName { return "", errors.New("channel name too long") }
+	return name, nil
+}
+
+// ListChannels returns the channels the member can see, in display order.
+// Owners and admins can include archived channels.
+func (s *GroupService) ListChannels(ctx context.Context, groupID, userID int64, includeArchived bool) ([]store.Channel, error) {
+	role, err := s.groups.GetMemberRole(ctx, groupID, userID)
+	if err != nil { return nil, err }
+	if role == "" { return nil, errors.New("not a group member") }
+	return s.groups.ListChannels(ctx, groupID, userID, role, includeArchived && isModerator(role))
+}
+
+// CreateChannel adds a channel at the end of the list; owners and admins
+// only.
+func (s *GroupService) CreateChannel(ctx context.Context, groupID, userID int64, name string, restricted bool) (*store.Channel, error) {
+	name, err := normalizeChannelName(name)
+	if err != nil { return nil, err }
+	var ch *store.Channel
+	err = s.uow.Do(ctx, func(tx *store.Tx) error {
+		if _, err := tx.Groups.LockGroup(ctx, groupID); err != nil { return err }
+		role, err := tx.Groups.GetMemberRole(ctx, groupID, userID)
+		if err != nil { return err }
+		if !isModerator(role) { return errors.New("only owner or admins can manage channels") }
+		ch, err = tx.Groups.CreateChannel(ctx, groupID, name, restricted)
+		if err != nil { return err }
+		after := map[string]any{"name": name, "restricted": restricted}
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: userID, Action: store.AuditChannelCreated, TargetID: ch.ID, After: after})
+	})
+	if err != nil { return nil, err }
+	return ch, nil
+}
+
+// moderatedChannel loads a channel of the group after checking the caller
+// may manage channels. Inside a transaction pass tx.Groups, after taking
+// the group lock.
+func moderatedChannel(ctx context.Context, groups *store.GroupStore, groupID, userID, channelID int64) (*store.Channel, error) {
+	role, err := groups.GetMemberRole(ctx, groupID, userID)
+	if err != nil { return nil, err }
+	if !isModerator(role) { return nil, errors.New("only owner or admins can manage channels") }
+	ch, err := groups.GetChannel(ctx, groupID, channelID)
+	if err != nil { return nil, err }
+	if ch == nil { return nil, ErrChannelNotFound }
+	return ch, nil
+}
+
+func (s *GroupService) RenameChannel(ctx context.Context, groupID, userID, channelID int64, name string) error {
+	name, err := normalizeChannelName(name)
+	if err != nil { return err }
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		if _, err := tx.Groups.LockGroup(ctx, groupID); err != nil { return err }
+		ch, err := moderatedChannel(ctx, tx.Groups, groupID, userID, channelID)
+		if err != nil { return err }
+		if err := tx.Groups.RenameChannel(ctx, ch.ID, name); err != nil { return err }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{
+			GroupID: groupID, ActorID: userID, Action: store.AuditChannelRenamed, TargetID: ch.ID,
+			Before: map[string]string{"name": ch.Name}, After: map[string]string{"name": name},
+		})
+	})
+}
+
+// SetChannelArchived archives or restores a channel. A group always keeps at
+// least one unarchived channel.
+func (s *GroupService) SetChannelArchived(ctx context.Context, groupID, userID, channelID int64, archived bool) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		if _, err := tx.Groups.LockGroup(ctx, groupID); err != nil { return err }
+		ch, err := moderatedChannel(ctx, tx.Groups, groupID, userID, channelID)
+		if err != nil { return err }
+		if ch.Archived() == archived { return nil }
+		if archived {
+			n, err := tx.Groups.CountActiveChannels(ctx, groupID)
+			if err != nil { return err }
+			if n <= 1 { return errors.New("cannot archive the group's last channel") }
+		}
+		if err := tx.Groups.SetChannelArchived(ctx, ch.ID, archived); err != nil { return err }
+		action := store.AuditChannelArchived
+		if !archived { action = store.AuditChannelUnarchived }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: userID, Action: action, TargetID: ch.ID})
+	})
+}
+
+// ReorderChannels sets the display order. ids must list every unarchived
+// channel exactly once.
+func (s *GroupService) ReorderChannels(ctx context.Context, groupID, userID int64, ids []int64) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		if _, err := tx.Groups.LockGroup(ctx, groupID); err != nil { return err }
+		role, err := tx.Groups.GetMemberRole(ctx, groupID, userID)
+		if err != nil { return err }
+		if !isModerator(role) { return errors.New("only owner or admins can manage channels") }
+		current, err := tx.Groups.ListChannels(ctx, groupID, userID, role, false)
+		if err != nil { return err }
+		want := map[int64]bool{}
+		before := make([]int64, 0, len(current))
+		for _, ch := range current {
+			want[ch.ID] = true
+			before = append(before, ch.ID)
+		}
+		if len(ids) != len(want) { return errors.New("channel_ids must list every active channel exactly once") }
+		for _, id := range ids {
+			if !want[id] { return errors.New("channel_ids must list every active channel exactly once") }
+			delete(want, id)
+		}
+		if err := tx.Groups.ReorderChannels(ctx, groupID, ids); err != nil { return err }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{
+			GroupID: groupID, ActorID: userID, Action: store.AuditChannelsReordered,
+			Before: map[string][]int64{"channel_ids": before}, After: map[string][]int64{"channel_ids": ids},
+		})
+	})
+}
+
+// AddChannelMember grants a group member access to a restricted channel.
+func (s *GroupService) AddChannelMember(ctx context.Context, groupID, userID, channelID, memberID int64) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		if _, err := tx.Groups.LockGroup(ctx, groupID); err != nil { return err }
+		ch, err := moderatedChannel(ctx, tx.Groups, groupID, userID, channelID)
+		if err != nil { return err }
+		if !ch.Restricted { return errors.New("channel is not restricted") }
+		isMember, err := tx.Groups.IsMember(ctx, groupID, memberID)
+		if err != nil { return err }
+		if !isMember { return errors.New("user is not a member") }
+		if err := tx.Groups.AddChannelMember(ctx, ch.ID, memberID); err != nil { return err }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: userID, Action: store.AuditChannelMemberAdded, TargetUserID: memberID, TargetID: ch.ID})
+	})
+}
+
+func (s *GroupService) RemoveChannelMember(ctx context.Context, groupID, userID, channelID, memberID int64) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		if _, err := tx.Groups.LockGroup(ctx, groupID); err != nil { return err }
+		ch, err := moderatedChannel(ctx, tx.Groups, groupID, userID, channelID)
+		if err != nil { return err }
+		if err := tx.Groups.RemoveChannelMember(ctx, ch.ID, memberID); err != nil { return err }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: userID, Action: store.AuditChannelMemberRemoved, TargetUserID: memberID, TargetID: ch.ID})
+	})
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"secure-messaging-backend/internal/store"
+)
+
+func TestSetChannelArchivedAuthorization(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	users := e.createUsers(t, 4)
+	owner, admin, member, otherOwner := users[0], users[1], users[2], users[3]
+	g := e.createGroup(t, owner, "open", 10, false)
+	other := e.createGroup(t, otherOwner, "open", 10, false)
+	for _, u := range []int64{admin, member} {
+		if _, err := e.groups.Join(ctx, JoinInput{GroupID: g.ID, UserID: u}); err != nil { t.Fatal(err) }
+	}
+	if err := e.groups.SetMemberRole(ctx, g.ID, owner, admin, store.RoleAdmin); err != nil { t.Fatal(err) }
+	ch, err := e.groups.CreateChannel(ctx, g.ID, owner, "announcements", false)
+	if err != nil { t.Fatal(err) }
+
+	if err := e.groups.SetChannelArchived(ctx, g.ID, member, ch.ID, true); err == nil { t.Error("member archived a channel") }
+	// The owner of another group can't reach the channel through their own
+	// group id, nor act on this group.
+	if err := e.groups.SetChannelArchived(ctx, other.ID, otherOwner, ch.ID, true); !errors.Is(err, ErrChannelNotFound) { t.Errorf("foreign group: err = %v, want %v", err, ErrChannelNotFound) }
+	if err := e.groups.SetChannelArchived(ctx, g.ID, otherOwner, ch.ID, true); err == nil { t.Error("non-member archived a channel") }
+	// A demoted admin loses the right at once.
+	if err := e.groups.SetMemberRole(ctx, g.ID, owner, admin, store.RoleMember); err != nil { t.Fatal(err) }
+	if err := e.groups.SetChannelArchived(ctx, g.ID, admin, ch.ID, true); err == nil { t.Error("demoted admin archived a channel") }
+
+	got, err := e.store.GetChannel(ctx, g.ID, ch.ID)
+	if err != nil { t.Fatal(err) }
+	if got.Archived() { t.Fatal("channel archived by an unauthorized caller") }
+	if err := e.groups.SetChannelArchived(ctx, g.ID, owner, ch.ID, true); err != nil { t.Fatalf("owner archive: %v", err) }
+	if got, err = e.store.GetChannel(ctx, g.ID, ch.ID); err != nil || !got.Archived() { t.Errorf("channel not archived: %+v, %v", got, err) }
+}
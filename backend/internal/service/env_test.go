@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+
+	"secure-messaging-backend/internal/config"
+	"secure-messaging-backend/internal/notify"
+	"secure-messaging-backend/internal/store"
+)
+
+
+type testEnv struct {
+	db       *sqlx.DB
+	cfg      *config.Config
+	groups   *GroupService
+	requests *JoinRequestService
+	messages *MessageService
+	store    *store.GroupStore
+	users    *store.UserStore
+}
+
+// newTestEnv migrates a fresh schema in the database at TEST_DATABASE_URL
+// and drops it when the test ends. Tests using it are skipped when the
+// variable is unset.
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" { t.Skip("TEST_DATABASE_URL not set") }
+	ctx := context.Background()
+	admin, err := sqlx.Open("postgres", dsn)
+	if err != nil { t.Fatal(err) }
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.ExecContext(ctx, `CREATE SCHEMA `+schema); err != nil { t.Fatal(err) }
+	db, err := sqlx.Open("postgres", withSearchPath(dsn, schema+",public"))
+	if err != nil { t.Fatal(err) }
+	t.Cleanup(func() {
+		db.Close()
+		_, _ = admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		admin.Close()
+	})
+	files, err := filepath.Glob("../../migrations/*.up.sql")
+	if err != nil { t.Fatal(err) }
+	sort.Strings(files)
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil { t.Fatal(err) }
+		if _, err := db.ExecContext(ctx, string(b)); err != nil { t.Fatalf("%s: %v", filepath.Base(f), err) }
+	}
+	cfg := &config.Config{MasterKey: "01234567890123456789012345678901", JoinRequestTTLDays: 30, InviteTTLDays: 7, MessageBurstLimit: 10, MessageBurstSeconds: 10}
+	uow := store.NewUnitOfWork(db)
+	groups := store.NewGroupStore(db)
+	users := store.NewUserStore(db)
+	notifier := notify.NewLogNotifier(zerolog.Nop())
+	return &testEnv{
+		db:       db,
+		cfg:      cfg,
+		groups:   NewGroupService(cfg, uow, groups, users, notifier),
+		requests: NewJoinRequestService(uow, groups),
+		messages: NewMessageService(cfg, uow, groups, store.NewMessageStore(db), notifier, zerolog.Nop()),
+		store:    groups,
+		users:    users,
+	}
+}
+
+// withSearchPath adds a search_path startup parameter to a URL or
+// key=value connection string.
+func withSearchPath(dsn, path string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		sep := "?"
+		if strings.Contains(dsn, "?") { sep = "&" }
+		return dsn + sep + "search_path=" + url.QueryEscape(path)
+	}
+	return dsn + " search_path=" + path
+}
+
+func (e *testEnv) createUsers(t *testing.T, n int) []int64 {
+	t.Helper()
+	ids := make([]int64, n)
+	for i := range ids {
+		u, err := e.users.CreateUser(context.Background(), fmt.Sprintf("user%d-%d@example.com", i, time.Now().UnixNano()), fmt.Sprintf("User %d", i), "x")
+		if err != nil { t.Fatal(err) }
+		ids[i] = u.ID
+	}
+	return ids
+}
+
+func (e *testEnv) createGroup(t *testing.T, ownerID int64, typ string, maxMembers int, waitlist bool) *store.Group {
+	t.Helper()
+	ctx := context.Background()
+	g, err := e.groups.CreateGroup(ctx, CreateGroupInput{Name: "capacity", OwnerID: ownerID, Type: typ, MaxMembers: maxMembers})
+	if err != nil { t.Fatal(err) }
+	if waitlist {
+		if _, err := e.groups.UpdateSettings(ctx, g.ID, ownerID, SettingsPatch{WaitlistEnabled: &waitlist}); err != nil { t.Fatal(err) }
+	}
+	return g
+}
+
+func (e *testEnv) memberCount(t *testing.T, groupID int64) int {
+	t.Helper()
+	n, err := e.store.CountMembers(context.Background(), groupID)
+	if err != nil { t.Fatal(err) }
+	return n
+}
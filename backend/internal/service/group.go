@@ -3,114 +3,578 @@ package service
 import (
 	"context"
 	"crypto/rand"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"secure-messaging-backend/internal/config"
 	appcrypto "secure-messaging-backend/internal/crypto"
+	"secure-messaging-backend/internal/notify"
 	"secure-messaging-backend/internal/store"
 )
 
 type GroupService struct {
-	groups *store.GroupStore
-	users  *store.UserStore
-	master []byte
+	cfg      *config.Config
+	uow      *store.UnitOfWork
+	groups   *store.GroupStore
+	users    *store.UserStore
+	notifier notify.Notifier
+	master   []byte
 }
 
-func NewGroupService(groups *store.GroupStore, users *store.UserStore, masterKey string) *GroupService {
-	return &GroupService{groups: groups, users: users, master: []byte(masterKey)}
+func NewGroupService(cfg *config.Config, uow *store.UnitOfWork, groups *store.GroupStore, users *store.UserStore, notifier notify.Notifier) *GroupService {
+	return &GroupService{cfg: cfg, uow: uow, groups: groups, users: users, notifier: notifier, master: []byte(cfg.MasterKey)}
 }
 
-const cooldownPrivateLeave = 48 * time.Hour
+// defaultPrivateRejoinCooldown is the rejoin cooldown new private and secret
+// groups start with; open groups start with none. Owners can change either.
+const defaultPrivateRejoinCooldown = 48 * time.Hour
 
-func (s *GroupService) CreateGroup(ctx context.Context, name string, ownerID int64, typ string, maxMembers int) (*store.Group, error) {
-	if name == "" { return nil, errors.New("name required") }
-	if typ != "open" && typ != "private" { return nil, errors.New("type must be open or private") }
-	if maxMembers <= 0 || maxMembers > 1000 { maxMembers = 100 }
+type CreateGroupInput struct {
+	Name           string
+	OwnerID        int64
+	Type           string
+	MaxMembers     int
+	MembersVisible bool
+	Description    string
+	Tags           []string
+}
+
+const maxGroupTags = 10
+
+// normalizeTags lowercases, trims and de-duplicates tags.
+func normalizeTags(in []string) []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, t := range in {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] { continue }
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+func (s *GroupService) CreateGroup(ctx context.Context, in CreateGroupInput) (*store.Group, error) {
+	if in.Name == "" { return nil, errors.New("name required") }
+	if in.Type != "open" && in.Type != "private" && in.Type != "secret" { return nil, errors.New("type must be open, private or secret") }
+	if in.MaxMembers <= 0 || in.MaxMembers > 1000 { in.MaxMembers = 100 }
+	var cooldown time.Duration
+	if in.Type != "open" { cooldown = defaultPrivateRejoinCooldown }
+	in.Tags = normalizeTags(in.Tags)
+	if len(in.Tags) > maxGroupTags { return nil, fmt.Errorf("at most %d tags allowed", maxGroupTags) }
 	// generate AES-128 key
 	gk := make([]byte, 16)
 	if _, err := rand.Read(gk); err != nil { return nil, err }
 	ct, nonce, err := appcrypto.WrapKey(s.master, gk)
 	if err != nil { return nil, err }
-	g, err := s.groups.CreateGroup(ctx, name, ownerID, typ, maxMembers, ct, nonce)
+	var g *store.Group
+	err = s.uow.Do(ctx, func(tx *store.Tx) error {
+		var err error
+		g, err = tx.Groups.CreateGroup(ctx, store.NewGroup{
+			Name:           in.Name,
+			OwnerID:        in.OwnerID,
+			Type:           in.Type,
+			MaxMembers:     in.MaxMembers,
+			EncryptedKey:   ct,
+			KeyNonce:       nonce,
+			MembersVisible: in.MembersVisible,
+			RejoinCooldown: cooldown,
+			Description:    strings.TrimSpace(in.Description),
+			Tags:           in.Tags,
+		})
+		if err != nil { return err }
+		// owner becomes member
+		if err := tx.Groups.AddMember(ctx, g.ID, in.OwnerID, store.RoleOwner); err != nil { return err }
+		if _, err := tx.Groups.CreateChannel(ctx, g.ID, store.DefaultChannelName, false); err != nil { return err }
+		return tx.Groups.RecordMembershipEvent(ctx, g.ID, in.OwnerID, in.OwnerID, store.EventJoined, nil)
+	})
 	if err != nil { return nil, err }
-	// owner becomes member
-	if err := s.groups.AddMember(ctx, g.ID, ownerID); err != nil { return nil, err }
 	return g, nil
 }
 
-func (s *GroupService) ListPublic(ctx context.Context, limit int) ([]store.Group, error) {
-	return s.groups.ListPublicGroups(ctx, limit)
+type PublicGroupPage struct {
+	Groups     []store.PublicGroup
+	NextCursor string
+}
+
+// ListPublic pages open groups newest first, annotated with the caller's
+// membership when callerID is non-zero.
+func (s *GroupService) ListPublic(ctx context.Context, callerID int64, cursor string, limit int) (*PublicGroupPage, error) {
+	after, err := store.DecodeCursor(cursor)
+	if err != nil { return nil, err }
+	if limit <= 0 || limit > 100 { limit = 50 }
+	rows, err := s.groups.ListPublicGroups(ctx, callerID, after, limit+1)
+	if err != nil { return nil, err }
+	page := &PublicGroupPage{Groups: rows}
+	if len(rows) > limit {
+		page.Groups = rows[:limit]
+		page.NextCursor = store.Cursor{ID: page.Groups[limit-1].ID}.Encode()
+	}
+	return page, nil
 }
 
-func (s *GroupService) ListOwned(ctx context.Context, ownerID int64) ([]store.Group, error) {
-	return s.groups.ListOwnedGroups(ctx, ownerID)
+type MemberGroupPage struct {
+	Groups     []store.MemberGroup
+	NextCursor string
 }
 
-func (s *GroupService) Join(ctx context.Context, groupID, userID int64) (string, error) {
-	g, err := s.groups.GetGroup(ctx, groupID)
-	if err != nil { return "", err }
-	if banned, err := s.groups.IsBanned(ctx, groupID, userID); err != nil { return "", err } else if banned { return "", errors.New("user is banned") }
-	isMember, err := s.groups.IsMember(ctx, groupID, userID)
-	if err != nil { return "", err }
-	if isMember { return "member", nil }
-	if g.Type == "open" {
-		count, err := s.groups.CountMembers(ctx, groupID)
-		if err != nil { return "", err }
-		if count >= g.MaxMembers { return "", errors.New("group full") }
-		if err := s.groups.AddMember(ctx, groupID, userID); err != nil { return "", err }
-		return "joined", nil
+// ListMine pages the groups the user belongs to, most recently active first.
+func (s *GroupService) ListMine(ctx context.Context, userID int64, role, cursor string, limit int) (*MemberGroupPage, error) {
+	if role != "" && !validRole(role) { return nil, errors.New("role must be owner, admin or member") }
+	after, err := store.DecodeCursor(cursor)
+	if err != nil { return nil, err }
+	if limit <= 0 || limit > 100 { limit = 50 }
+	rows, err := s.groups.ListMemberGroups(ctx, userID, role, after, limit+1)
+	if err != nil { return nil, err }
+	page := &MemberGroupPage{Groups: rows}
+	if len(rows) > limit {
+		page.Groups = rows[:limit]
+		last := page.Groups[limit-1]
+		page.NextCursor = store.TimeCursor(last.LastActivityAt, last.ID).Encode()
 	}
-	// private: enforce 48h cooldown from last_left_at
-	if last, err := s.groups.GetLastLeft(ctx, groupID, userID); err != nil { return "", err } else if last != nil {
-		if time.Since(*last) < cooldownPrivateLeave { return "", fmt.Errorf("cooldown active: try after %s", last.Add(cooldownPrivateLeave).Format(time.RFC3339)) }
+	return page, nil
+}
+
+type SearchGroupsInput struct {
+	CallerID int64
+	Query    string
+	Tags     []string
+	Sort     string
+	Cursor   string
+	Limit    int
+}
+
+type GroupPage struct {
+	Groups     []store.Group
+	NextCursor string
+}
+
+// Search is public discovery over open groups, excluding groups the caller is
+// banned from.
+func (s *GroupService) Search(ctx context.Context, in SearchGroupsInput) (*GroupPage, error) {
+	if in.Sort == "" { in.Sort = store.SortByMembers }
+	if in.Sort != store.SortByMembers && in.Sort != store.SortByActivity { return nil, errors.New("sort must be members or activity") }
+	after, err := store.DecodeCursor(in.Cursor)
+	if err != nil { return nil, err }
+	if in.Limit <= 0 || in.Limit > 100 { in.Limit = 50 }
+	rows, err := s.groups.SearchGroups(ctx, store.GroupSearch{
+		Query:    strings.TrimSpace(in.Query),
+		Tags:     normalizeTags(in.Tags),
+		Sort:     in.Sort,
+		After:    after,
+		CallerID: in.CallerID,
+		Limit:    in.Limit + 1,
+	})
+	if err != nil { return nil, err }
+	page := &GroupPage{Groups: rows}
+	if len(rows) > in.Limit {
+		page.Groups = rows[:in.Limit]
+		last := page.Groups[in.Limit-1]
+		c := store.Cursor{Key: int64(last.MemberCount), ID: last.ID}
+		if in.Sort == store.SortByActivity { c = store.TimeCursor(last.LastActivityAt, last.ID) }
+		page.NextCursor = c.Encode()
 	}
-	jr, err := s.groups.CreateJoinRequest(ctx, groupID, userID)
-	if err != nil { return "", err }
-	return fmt.Sprintf("join_requested:%d", jr.ID), nil
+	return page, nil
 }
 
-func (s *GroupService) Leave(ctx context.Context, groupID, userID int64) error {
-	g, err := s.groups.GetGroup(ctx, groupID)
-	if err != nil { return err }
-	if g.OwnerID == userID {
-		ok, err := s.groups.OwnerLeaveAllowed(ctx, groupID)
+type JoinInput struct {
+	GroupID int64
+	UserID  int64
+	Message string
+	Answers []AnswerInput
+}
+
+// Join adds the user to an open group, or files a join request for a private
+// one, after applying the group's rejoin cooldown and join policies. The
+// group row is locked for the duration so concurrent joins cannot push
+// membership past max_members.
+func (s *GroupService) Join(ctx context.Context, in JoinInput) (string, error) {
+	groupID, userID := in.GroupID, in.UserID
+	in.Message = strings.TrimSpace(in.Message)
+	var status string
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		g, err := tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		if g.Archived() { return ErrGroupArchived }
+		if banned, err := tx.Groups.IsBanned(ctx, groupID, userID); err != nil { return err } else if banned { return errors.New("user is banned") }
+		isMember, err := tx.Groups.IsMember(ctx, groupID, userID)
+		if err != nil { return err }
+		if isMember { status = "member"; return nil }
+		if g.Type == "secret" { return ErrGroupNotFound }
+		if cd := g.RejoinCooldown(); cd > 0 {
+			if last, err := tx.Groups.LastDepartureAt(ctx, groupID, userID); err != nil { return err } else if last != nil {
+				if time.Since(*last) < cd { return fmt.Errorf("cooldown active: try after %s", last.Add(cd).Format(time.RFC3339)) }
+			}
+		}
+		if g.RequireVouch {
+			ok, err := tx.Groups.HasVouch(ctx, groupID, userID)
+			if err != nil { return err }
+			if !ok { return errors.New("a current member must vouch for you before you can join") }
+		}
+		admit := g.Type == "open"
+		if !admit && len(g.AutoApproveDomains) > 0 {
+			u, err := tx.Users.GetUserByID(ctx, userID)
+			if err != nil { return err }
+			admit = emailInDomains(u.Email, g.AutoApproveDomains)
+		}
+		if admit {
+			count, err := tx.Groups.CountMembers(ctx, groupID)
+			if err != nil { return err }
+			if count >= g.MaxMembers {
+				if g.Type != "open" || !g.WaitlistEnabled { return errors.New("group full") }
+				pos, err := tx.Groups.AddToWaitlist(ctx, groupID, userID)
+				if err != nil { return err }
+				status = fmt.Sprintf("waitlisted:%d", pos)
+				return nil
+			}
+			if err := tx.Groups.AddMember(ctx, groupID, userID, store.RoleMember); err != nil { return err }
+			if err := tx.Groups.RemoveFromWaitlist(ctx, groupID, userID); err != nil { return err }
+			status = "joined"
+			event := store.EventJoined
+			if g.Type != "open" { event = store.EventApproved }
+			return tx.Groups.RecordMembershipEvent(ctx, groupID, userID, userID, event, nil)
+		}
+		if jr, err := tx.Groups.GetPendingJoinRequest(ctx, groupID, userID); err != nil { return err } else if jr != nil {
+			status = fmt.Sprintf("join_requested:%d", jr.ID)
+			return nil
+		}
+		if g.RequireJoinMessage && in.Message == "" { return errors.New("this group requires a message with join requests") }
+		questions, err := tx.Groups.ListQuestions(ctx, groupID)
+		if err != nil { return err }
+		answers, err := buildAnswers(questions, in.Answers)
 		if err != nil { return err }
-		if !ok { return errors.New("owner cannot leave unless sole member; transfer ownership or delete group") }
+		if err := tx.Groups.ExpireLapsedJoinRequest(ctx, groupID, userID); err != nil { return err }
+		ttl := time.Duration(s.cfg.JoinRequestTTLDays) * 24 * time.Hour
+		jr, err := tx.Groups.CreateJoinRequest(ctx, store.NewJoinRequest{
+			GroupID:     groupID,
+			RequesterID: userID,
+			Message:     in.Message,
+			Answers:     answers,
+			ExpiresAt:   time.Now().Add(ttl),
+		})
+		if err != nil { return err }
+		status = fmt.Sprintf("join_requested:%d", jr.ID)
+		return nil
+	})
+	return status, err
+}
+
+// emailInDomains reports whether email's domain is one of domains.
+func emailInDomains(email string, domains []string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 { return false }
+	domain := strings.ToLower(email[at+1:])
+	for _, d := range domains {
+		if domain == d { return true }
 	}
-	if err := s.groups.RemoveMember(ctx, groupID, userID); err != nil { return err }
-	// mark last_left_at
-	_ = s.groups.UpdateLastLeft(ctx, groupID, userID, time.Now())
-	return nil
+	return false
 }
 
-func (s *GroupService) TransferOwner(ctx context.Context, groupID, currentOwner, newOwner int64) error {
-	g, err := s.groups.GetGroup(ctx, groupID)
-	if err != nil { return err }
-	if g.OwnerID != currentOwner { return errors.New("only owner can transfer") }
-	isMember, err := s.groups.IsMember(ctx, groupID, newOwner)
+// Leave removes the user and admits the next waitlisted users into the
+// freed seat. An owner who is not the sole member hands the group to the
+// successor chosen by its succession rules.
+func (s *GroupService) Leave(ctx context.Context, groupID, userID int64) error {
+	var g *store.Group
+	var admitted []int64
+	var successor int64
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		var err error
+		g, err = tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		role, err := tx.Groups.GetMemberRole(ctx, groupID, userID)
+		if err != nil { return err }
+		if role == "" { return errors.New("not a group member") }
+		if g.OwnerID == userID {
+			ok, err := tx.Groups.OwnerLeaveAllowed(ctx, groupID)
+			if err != nil { return err }
+			if !ok {
+				successor, err = succeedOwner(ctx, tx, g)
+				if err != nil { return err }
+				if successor == 0 { return errors.New("no eligible successor; transfer ownership or delete group") }
+			}
+		}
+		if err := tx.Groups.RemoveMember(ctx, groupID, userID); err != nil { return err }
+		if err := tx.Groups.RecordMembershipEvent(ctx, groupID, userID, userID, store.EventLeft, nil); err != nil { return err }
+		admitted, err = admitFromWaitlist(ctx, tx, g)
+		return err
+	})
 	if err != nil { return err }
-	if !isMember { return errors.New("new owner must be a member") }
-	return s.groups.TransferOwner(ctx, groupID, newOwner)
+	s.notifyAdmitted(ctx, g, admitted)
+	s.notifySuccessor(ctx, g, successor)
+	return nil
 }
 
 func (s *GroupService) Delete(ctx context.Context, groupID, ownerID int64) error {
-	g, err := s.groups.GetGroup(ctx, groupID)
-	if err != nil { return err }
-	if g.OwnerID != ownerID { return errors.New("only owner can delete") }
-	ok, err := s.groups.OwnerLeaveAllowed(ctx, groupID)
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		g, err := tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		if g.OwnerID != ownerID { return errors.New("only owner can delete") }
+		ok, err := tx.Groups.OwnerLeaveAllowed(ctx, groupID)
+		if err != nil { return err }
+		if !ok { return errors.New("owner can delete only when sole member") }
+		if err := tx.Groups.DeleteGroup(ctx, groupID); err != nil { return err }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: ownerID, Action: store.AuditGroupDeleted})
+	})
+}
+
+// SettingsPatch updates group settings; nil fields are left unchanged.
+type SettingsPatch struct {
+	MembersVisible        *bool
+	RejoinCooldownSeconds *int
+	AutoApproveDomains    *[]string
+	RequireJoinMessage    *bool
+	RequireVouch          *bool
+	WaitlistEnabled       *bool
+	SuccessorID           *int64
+	SuccessionRules       *[]string
+	OwnerInactiveDays     *int
+	AnnouncementOnly      *bool
+	SlowModeSeconds       *int
+	HoldNewMemberHours    *int
+	HoldMinApproved       *int
+}
+
+// UpdateSettings applies patch to the group's settings; owner only.
+func (s *GroupService) UpdateSettings(ctx context.Context, groupID, ownerID int64, patch SettingsPatch) (*store.GroupSettings, error) {
+	var out store.GroupSettings
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		g, err := tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		if g.OwnerID != ownerID { return errors.New("only owner can change settings") }
+		gs := g.GroupSettings
+		if patch.MembersVisible != nil { gs.MembersVisible = *patch.MembersVisible }
+		if patch.RejoinCooldownSeconds != nil {
+			if *patch.RejoinCooldownSeconds < 0 { return errors.New("rejoin_cooldown_seconds must not be negative") }
+			gs.RejoinCooldownSeconds = *patch.RejoinCooldownSeconds
+		}
+		if patch.AutoApproveDomains != nil {
+			domains := normalizeTags(*patch.AutoApproveDomains)
+			for _, d := range domains {
+				if strings.ContainsAny(d, "@ ") || !strings.Contains(d, ".") { return fmt.Errorf("invalid email domain %q", d) }
+			}
+			gs.AutoApproveDomains = domains
+		}
+		if patch.RequireJoinMessage != nil { gs.RequireJoinMessage = *patch.RequireJoinMessage }
+		if patch.RequireVouch != nil { gs.RequireVouch = *patch.RequireVouch }
+		if patch.WaitlistEnabled != nil { gs.WaitlistEnabled = *patch.WaitlistEnabled }
+		if patch.SuccessorID != nil {
+			gs.SuccessorID = nil
+			if id := *patch.SuccessorID; id != 0 {
+				if id == ownerID { return errors.New("successor must not be the owner") }
+				isMember, err := tx.Groups.IsMember(ctx, groupID, id)
+				if err != nil { return err }
+				if !isMember { return errors.New("successor must be a member") }
+				gs.SuccessorID = &id
+			}
+		}
+		if patch.SuccessionRules != nil {
+			if err := validSuccessionRules(*patch.SuccessionRules); err != nil { return err }
+			gs.SuccessionRules = *patch.SuccessionRules
+		}
+		if patch.OwnerInactiveDays != nil {
+			if *patch.OwnerInactiveDays < 0 { return errors.New("owner_inactive_days must not be negative") }
+			gs.OwnerInactiveDays = *patch.OwnerInactiveDays
+		}
+		if patch.AnnouncementOnly != nil { gs.AnnouncementOnly = *patch.AnnouncementOnly }
+		if patch.SlowModeSeconds != nil {
+			if *patch.SlowModeSeconds < 0 || *patch.SlowModeSeconds > maxSlowModeSeconds {
+				return fmt.Errorf("slow_mode_seconds must be between 0 and %d", maxSlowModeSeconds)
+			}
+			gs.SlowModeSeconds = *patch.SlowModeSeconds
+		}
+		if patch.HoldNewMemberHours != nil {
+			if *patch.HoldNewMemberHours < 0 { return errors.New("hold_new_member_hours must not be negative") }
+			gs.HoldNewMemberHours = *patch.HoldNewMemberHours
+		}
+		if patch.HoldMinApproved != nil {
+			if *patch.HoldMinApproved < 0 { return errors.New("hold_min_approved_messages must not be negative") }
+			gs.HoldMinApproved = *patch.HoldMinApproved
+		}
+		if err := tx.Groups.UpdateSettings(ctx, groupID, gs); err != nil { return err }
+		out = gs
+		before, after := settingsChanges(g.GroupSettings, gs)
+		if len(after) == 0 { return nil }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: ownerID, Action: store.AuditSettingsUpdated, Before: before, After: after})
+	})
+	if err != nil { return nil, err }
+	return &out, nil
+}
+
+// Vouch records that a member vouches for a prospective member, satisfying
+// the group's require_vouch policy.
+func (s *GroupService) Vouch(ctx context.Context, groupID, voucherID, userID int64) error {
+	if voucherID == userID { return errors.New("cannot vouch for yourself") }
+	isMember, err := s.groups.IsMember(ctx, groupID, voucherID)
 	if err != nil { return err }
-	if !ok { return errors.New("owner can delete only when sole member") }
-	return s.groups.DeleteGroup(ctx, groupID)
+	if !isMember { return errors.New("only members can vouch") }
+	if _, err := s.users.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) { return errors.New("user not found") }
+		return err
+	}
+	return s.groups.AddVouch(ctx, groupID, userID, voucherID)
+}
+
+func (s *GroupService) RevokeVouch(ctx context.Context, groupID, voucherID, userID int64) error {
+	return s.groups.RemoveVouch(ctx, groupID, userID, voucherID)
+}
+
+// Unban lifts a ban, granting any open appeal; the group's rejoin cooldown
+// runs from this moment.
+func (s *GroupService) Unban(ctx context.Context, groupID, ownerID, targetUser int64) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		g, err := tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		if g.OwnerID != ownerID { return errors.New("only owner can lift bans") }
+		if err := tx.Groups.RemoveBan(ctx, groupID, targetUser); err != nil {
+			if errors.Is(err, sql.ErrNoRows) { return errors.New("user is not banned") }
+			return err
+		}
+		if err := tx.Groups.GrantOpenAppeal(ctx, groupID, targetUser, ownerID); err != nil { return err }
+		if err := tx.Groups.RecordMembershipEvent(ctx, groupID, targetUser, ownerID, store.EventUnbanned, nil); err != nil { return err }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: ownerID, Action: store.AuditMemberUnbanned, TargetUserID: targetUser})
+	})
 }
 
+// Banish bans and removes the user, then admits the next waitlisted users
+// into the freed seat.
 func (s *GroupService) Banish(ctx context.Context, groupID, ownerID, targetUser int64, reason *string) error {
-	g, err := s.groups.GetGroup(ctx, groupID)
+	var g *store.Group
+	var admitted []int64
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		var err error
+		g, err = tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		if g.OwnerID != ownerID { return errors.New("only owner can banish") }
+		admitted, err = banishTx(ctx, tx, g, ownerID, targetUser, reason)
+		return err
+	})
 	if err != nil { return err }
-	if g.OwnerID != ownerID { return errors.New("only owner can banish") }
-	if targetUser == ownerID { return errors.New("cannot banish owner") }
-	if err := s.groups.AddBan(ctx, groupID, targetUser, reason); err != nil { return err }
-	_ = s.groups.RemoveMember(ctx, groupID, targetUser)
-	_ = s.groups.UpdateLastLeft(ctx, groupID, targetUser, time.Now())
+	s.notifyAdmitted(ctx, g, admitted)
 	return nil
 }
+
+// banishTx bans and removes targetUser on behalf of actorID, who the caller
+// has authorized, and returns the waitlisted users admitted into the freed
+// seat. The caller holds the group lock.
+func banishTx(ctx context.Context, tx *store.Tx, g *store.Group, actorID, targetUser int64, reason *string) ([]int64, error) {
+	if targetUser == g.OwnerID { return nil, errors.New("cannot banish owner") }
+	if err := tx.Groups.AddBan(ctx, g.ID, targetUser, reason); err != nil { return nil, err }
+	if err := tx.Groups.RemoveMember(ctx, g.ID, targetUser); err != nil { return nil, err }
+	if err := tx.Groups.RemoveFromWaitlist(ctx, g.ID, targetUser); err != nil { return nil, err }
+	if err := tx.Groups.RecordMembershipEvent(ctx, g.ID, targetUser, actorID, store.EventBanished, nil); err != nil { return nil, err }
+	if err := tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: g.ID, ActorID: actorID, Action: store.AuditMemberBanished, TargetUserID: targetUser, Reason: reason}); err != nil { return nil, err }
+	return admitFromWaitlist(ctx, tx, g)
+}
+
+type ListMembersInput struct {
+	GroupID     int64
+	RequesterID int64
+	Role        string
+	Query       string
+	Cursor      string
+	Limit       int
+}
+
+var (
+	ErrInvalidRole   = errors.New("role must be owner, admin or member")
+	ErrMembersHidden = errors.New("members are visible to group members only")
+)
+
+type MemberPage struct {
+	Members    []store.Member
+	NextCursor string
+	Counts     map[string]int
+	Total      int
+}
+
+// ListMembers pages through a group's members ordered by join date. Private
+// groups are visible to members only; open groups also to anyone when the
+// owner has enabled members_visible.
+func (s *GroupService) ListMembers(ctx context.Context, in ListMembersInput) (*MemberPage, error) {
+	if in.Role != "" && !validRole(in.Role) { return nil, ErrInvalidRole }
+	g, err := s.groups.GetGroup(ctx, in.GroupID)
+	if errors.Is(err, sql.ErrNoRows) { return nil, ErrGroupNotFound }
+	if err != nil { return nil, err }
+	role, err := s.groups.GetMemberRole(ctx, in.GroupID, in.RequesterID)
+	if err != nil { return nil, err }
+	if role == "" && !(g.Type == "open" && g.MembersVisible) { return nil, ErrMembersHidden }
+	after, err := store.DecodeCursor(in.Cursor)
+	if err != nil { return nil, err }
+	if in.Limit <= 0 || in.Limit > 100 { in.Limit = 50 }
+	rows, err := s.groups.ListMembers(ctx, store.MemberFilter{GroupID: in.GroupID, Role: in.Role, Query: in.Query, After: after, Limit: in.Limit + 1})
+	if err != nil { return nil, err }
+	page := &MemberPage{Members: rows}
+	if len(rows) > in.Limit {
+		page.Members = rows[:in.Limit]
+		last := page.Members[in.Limit-1]
+		page.NextCursor = store.TimeCursor(last.JoinedAt, last.UserID).Encode()
+	}
+	counts, err := s.groups.CountMembersByRole(ctx, in.GroupID)
+	if err != nil { return nil, err }
+	page.Counts = counts
+	for _, n := range counts { page.Total += n }
+	return page, nil
+}
+
+// SetMemberRole promotes a member to admin or demotes an admin; owner only.
+func (s *GroupService) SetMemberRole(ctx context.Context, groupID, ownerID, targetUser int64, role string) error {
+	if role != store.RoleAdmin && role != store.RoleMember { return errors.New("role must be admin or member") }
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		g, err := tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		if g.OwnerID != ownerID { return errors.New("only owner can change roles") }
+		if targetUser == g.OwnerID { return errors.New("use transfer-owner to change the owner") }
+		prev, err := tx.Groups.GetMemberRole(ctx, groupID, targetUser)
+		if err != nil { return err }
+		if err := tx.Groups.SetMemberRole(ctx, groupID, targetUser, role); err != nil {
+			if errors.Is(err, sql.ErrNoRows) { return errors.New("user is not a member") }
+			return err
+		}
+		if err := tx.Groups.RecordMembershipEvent(ctx, groupID, targetUser, ownerID, store.EventRoleChanged, &role); err != nil { return err }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{
+			GroupID: groupID, ActorID: ownerID, Action: store.AuditRoleChanged, TargetUserID: targetUser,
+			Before: roleChange(prev), After: roleChange(role),
+		})
+	})
+}
+
+// recordRoleChanges writes a role_changed event for each user in roles.
+func recordRoleChanges(ctx context.Context, tx *store.Tx, groupID, actorID int64, roles map[int64]string) error {
+	for uid, role := range roles {
+		role := role
+		if err := tx.Groups.RecordMembershipEvent(ctx, groupID, uid, actorID, store.EventRoleChanged, &role); err != nil { return err }
+	}
+	return nil
+}
+
+type MembershipTimeline struct {
+	Events     []store.MembershipEvent
+	NextCursor string
+}
+
+// MembershipTimeline pages a group's membership history, newest first, for
+// owners and admins. userID optionally narrows it to one member.
+func (s *GroupService) MembershipTimeline(ctx context.Context, groupID, requesterID, userID int64, cursor string, limit int) (*MembershipTimeline, error) {
+	role, err := s.groups.GetMemberRole(ctx, groupID, requesterID)
+	if err != nil { return nil, err }
+	if !isModerator(role) { return nil, errors.New("only owner or admins can view membership history") }
+	after, err := store.DecodeCursor(cursor)
+	if err != nil { return nil, err }
+	if limit <= 0 || limit > 100 { limit = 50 }
+	rows, err := s.groups.ListMembershipEvents(ctx, groupID, userID, after, limit+1)
+	if err != nil { return nil, err }
+	page := &MembershipTimeline{Events: rows}
+	if len(rows) > limit {
+		page.Events = rows[:limit]
+		page.NextCursor = store.Cursor{ID: page.Events[limit-1].ID}.Encode()
+	}
+	return page, nil
+}
+
+func validRole(r string) bool { return r == store.RoleOwner || r == store.RoleAdmin || r == store.RoleMember }
+
+// isModerator reports whether a member role carries moderation rights.
+func isModerator(r string) bool { return r == store.RoleOwner || r == store.RoleAdmin }
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLeaveRequiresMembership(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	users := e.createUsers(t, 2)
+	owner, outsider := users[0], users[1]
+	g := e.createGroup(t, owner, "private", 10, false)
+
+	if err := e.groups.Leave(ctx, g.ID, outsider); err == nil || err.Error() != "not a group member" { t.Fatalf("leave by outsider: err = %v", err) }
+	// No departure was recorded, so the private group's rejoin cooldown
+	// doesn't apply and the outsider can still ask to join.
+	var events int
+	if err := e.db.GetContext(ctx, &events, `SELECT COUNT(*) FROM membership_events WHERE group_id=$1 AND user_id=$2`, g.ID, outsider); err != nil { t.Fatal(err) }
+	if events != 0 { t.Errorf("membership events for outsider = %d, want 0", events) }
+	if _, err := e.groups.Join(ctx, JoinInput{GroupID: g.ID, UserID: outsider}); err != nil { t.Errorf("join after refused leave: %v", err) }
+	if got := e.memberCount(t, g.ID); got != 1 { t.Errorf("members = %d, want 1", got) }
+}
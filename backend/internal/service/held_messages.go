@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"secure-messaging-backend/internal/store"
+)
+
+// shouldHold reports whether a message from member goes to the review queue
+// under the group's hold rules: joined too recently, or too few approved
+// messages so far.
+func (s *MessageService) shouldHold(ctx context.Context, g *store.Group, member *store.GroupMember) (bool, error) {
+	if h := g.HoldNewMemberHours; h > 0 && member.JoinedAt.After(time.Now().Add(-time.Duration(h)*time.Hour)) { return true, nil }
+	if g.HoldMinApproved > 0 {
+		n, err := s.msgs.CountApproved(ctx, g.ID, member.UserID)
+		if err != nil { return false, err }
+		if n < g.HoldMinApproved { return true, nil }
+	}
+	return false, nil
+}
+
+func (s *MessageService) requireModerator(ctx context.Context, groupID, userID int64) error {
+	role, err := s.groups.GetMemberRole(ctx, groupID, userID)
+	if err != nil { return err }
+	if !isModerator(role) { return errors.New("only owner or admins can review held messages") }
+	return nil
+}
+
+type HeldMessagePage struct {
+	Messages   []MessageDTO
+	NextCursor string
+}
+
+// HeldMessages pages the group's review queue, oldest first, for owners and
+// admins.
+func (s *MessageService) HeldMessages(ctx context.Context, groupID, moderatorID int64, cursor string, limit int) (*HeldMessagePage, error) {
+	if err := s.requireModerator(ctx, groupID, moderatorID); err != nil { return nil, err }
+	after, err := store.DecodeCursor(cursor)
+	if err != nil { return nil, err }
+	if limit <= 0 || limit > 100 { limit = 50 }
+	g, err := s.groups.GetGroup(ctx, groupID)
+	if err != nil { return nil, err }
+	rows, err := s.msgs.ListHeld(ctx, groupID, after, limit+1)
+	if err != nil { return nil, err }
+	page := &HeldMessagePage{}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		page.NextCursor = store.Cursor{ID: rows[limit-1].ID}.Encode()
+	}
+	page.Messages, err = s.decrypt(g, rows)
+	if err != nil { return nil, err }
+	return page, nil
+}
+
+// ReviewHeldMessage publishes (approve) or rejects a held message.
+func (s *MessageService) ReviewHeldMessage(ctx context.Context, groupID, moderatorID, messageID int64, approve bool, reason *string) error {
+	if err := s.requireModerator(ctx, groupID, moderatorID); err != nil { return err }
+	status, action := store.MessageApproved, store.AuditMessageApproved
+	if !approve { status, action = store.MessageRejected, store.AuditMessageRejected }
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		m, err := tx.Messages.ReviewHeld(ctx, groupID, messageID, moderatorID, status, reason)
+		if err != nil { return err }
+		if m == nil { return errors.New("message is not held for review") }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{
+			GroupID: groupID, ActorID: moderatorID, Action: action, TargetUserID: m.SenderID, TargetID: m.ID, Reason: reason,
+			Before: map[string]string{"status": store.MessageHeld}, After: map[string]string{"status": status},
+		})
+	})
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"time"
+
+	"secure-messaging-backend/internal/notify"
+	"secure-messaging-backend/internal/store"
+)
+
+// ErrGroupNotFound is returned for missing groups and for secret groups the
+// caller is not a member of, so probing ids reveals nothing.
+var ErrGroupNotFound = errors.New("group not found")
+
+func (s *GroupService) requireModerator(ctx context.Context, groupID, userID int64, action string) error {
+	role, err := s.groups.GetMemberRole(ctx, groupID, userID)
+	if err != nil { return err }
+	if !isModerator(role) { return errors.New("only owner or admins can " + action) }
+	return nil
+}
+
+// InviteUser sends a direct invitation, valid for INVITE_TTL_DAYS.
+func (s *GroupService) InviteUser(ctx context.Context, groupID, inviterID, userID int64) (*store.Invite, error) {
+	if err := s.requireModerator(ctx, groupID, inviterID, "invite"); err != nil { return nil, err }
+	var g *store.Group
+	var inv *store.Invite
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		var err error
+		g, err = tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		if g.Archived() { return ErrGroupArchived }
+		if _, err := tx.Users.GetUserByID(ctx, userID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) { return errors.New("user not found") }
+			return err
+		}
+		if isMember, err := tx.Groups.IsMember(ctx, groupID, userID); err != nil { return err } else if isMember { return errors.New("user is already a member") }
+		if banned, err := tx.Groups.IsBanned(ctx, groupID, userID); err != nil { return err } else if banned { return errors.New("user is banned") }
+		ttl := time.Duration(s.cfg.InviteTTLDays) * 24 * time.Hour
+		inv, err = tx.Groups.CreateDirectInvite(ctx, groupID, inviterID, userID, time.Now().Add(ttl))
+		if err != nil { return err }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: inviterID, Action: store.AuditInviteCreated, TargetUserID: userID, TargetID: inv.ID})
+	})
+	if err != nil { return nil, err }
+	_ = s.notifier.SendToUsers(ctx, []int64{userID}, notify.Payload{
+		Type:  "group_invite",
+		Title: "Group invitation",
+		Body:  "You have been invited to join " + g.Name + ".",
+		Data:  map[string]string{"group_id": strconv.FormatInt(groupID, 10), "invite_id": strconv.FormatInt(inv.ID, 10)},
+	})
+	return inv, nil
+}
+
+// CreateInviteLink issues a link token. maxUses nil means unlimited; ttl 0
+// uses INVITE_TTL_DAYS.
+func (s *GroupService) CreateInviteLink(ctx context.Context, groupID, inviterID int64, maxUses *int, ttl time.Duration) (*store.Invite, error) {
+	if err := s.requireModerator(ctx, groupID, inviterID, "create invite links"); err != nil { return nil, err }
+	if maxUses != nil && *maxUses <= 0 { return nil, errors.New("max_uses must be positive") }
+	if ttl < 0 { return nil, errors.New("expiry must not be negative") }
+	if ttl == 0 { ttl = time.Duration(s.cfg.InviteTTLDays) * 24 * time.Hour }
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil { return nil, err }
+	var inv *store.Invite
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		var err error
+		inv, err = tx.Groups.CreateInviteLink(ctx, groupID, inviterID, base64.RawURLEncoding.EncodeToString(b), maxUses, time.Now().Add(ttl))
+		if err != nil { return err }
+		after := map[string]any{"max_uses": maxUses, "expires_at": inv.ExpiresAt}
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: inviterID, Action: store.AuditInviteLinkCreated, TargetID: inv.ID, After: after})
+	})
+	if err != nil { return nil, err }
+	return inv, nil
+}
+
+// ListInvites returns the group's redeemable invitations and links.
+func (s *GroupService) ListInvites(ctx context.Context, groupID, userID int64) ([]store.Invite, error) {
+	if err := s.requireModerator(ctx, groupID, userID, "view invites"); err != nil { return nil, err }
+	return s.groups.ListGroupInvites(ctx, groupID)
+}
+
+func (s *GroupService) RevokeInvite(ctx context.Context, groupID, userID, inviteID int64) error {
+	if err := s.requireModerator(ctx, groupID, userID, "revoke invites"); err != nil { return err }
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		inv, err := tx.Groups.LockInvite(ctx, inviteID)
+		if err != nil { return err }
+		if inv == nil || inv.GroupID != groupID { return errors.New("invite not found") }
+		if err := tx.Groups.RevokeInvite(ctx, inviteID); err != nil { return err }
+		var invitee int64
+		if inv.InviteeID != nil { invitee = *inv.InviteeID }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: userID, Action: store.AuditInviteRevoked, TargetUserID: invitee, TargetID: inviteID})
+	})
+}
+
+func (s *GroupService) ListMyInvites(ctx context.Context, userID int64) ([]store.UserInvite, error) {
+	return s.groups.ListUserInvites(ctx, userID)
+}
+
+// AcceptInvite joins the group the caller was directly invited to.
+func (s *GroupService) AcceptInvite(ctx context.Context, userID, inviteID int64) (int64, error) {
+	var groupID int64
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		inv, err := tx.Groups.LockInvite(ctx, inviteID)
+		if err != nil { return err }
+		if inv == nil || inv.InviteeID == nil || *inv.InviteeID != userID { return errors.New("invite not found") }
+		groupID = inv.GroupID
+		return redeemInvite(ctx, tx, inv, userID)
+	})
+	return groupID, err
+}
+
+// DeclineInvite turns down a direct invitation.
+func (s *GroupService) DeclineInvite(ctx context.Context, userID, inviteID int64) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		inv, err := tx.Groups.LockInvite(ctx, inviteID)
+		if err != nil { return err }
+		if inv == nil || inv.InviteeID == nil || *inv.InviteeID != userID { return errors.New("invite not found") }
+		return tx.Groups.RevokeInvite(ctx, inviteID)
+	})
+}
+
+// RedeemInviteLink joins the group behind an invite link token.
+func (s *GroupService) RedeemInviteLink(ctx context.Context, userID int64, token string) (int64, error) {
+	var groupID int64
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		inv, err := tx.Groups.LockInviteByToken(ctx, token)
+		if err != nil { return err }
+		if inv == nil { return errors.New("invite not found") }
+		groupID = inv.GroupID
+		return redeemInvite(ctx, tx, inv, userID)
+	})
+	return groupID, err
+}
+
+// redeemInvite adds userID to the invite's group. Invites bypass the join
+// policies and rejoin cooldown but not bans, archiving or capacity. The
+// caller holds the invite row lock.
+func redeemInvite(ctx context.Context, tx *store.Tx, inv *store.Invite, userID int64) error {
+	if !inv.Usable(time.Now()) { return errors.New("invite is no longer valid") }
+	g, err := tx.Groups.LockGroup(ctx, inv.GroupID)
+	if errors.Is(err, sql.ErrNoRows) { return ErrGroupNotFound }
+	if err != nil { return err }
+	if g.Archived() { return ErrGroupArchived }
+	if banned, err := tx.Groups.IsBanned(ctx, g.ID, userID); err != nil { return err } else if banned { return errors.New("user is banned") }
+	if isMember, err := tx.Groups.IsMember(ctx, g.ID, userID); err != nil { return err } else if isMember { return errors.New("already a member") }
+	count, err := tx.Groups.CountMembers(ctx, g.ID)
+	if err != nil { return err }
+	if count >= g.MaxMembers { return errors.New("group full") }
+	if err := tx.Groups.AddMember(ctx, g.ID, userID, store.RoleMember); err != nil { return err }
+	if err := tx.Groups.RemoveFromWaitlist(ctx, g.ID, userID); err != nil { return err }
+	if err := tx.Groups.UseInvite(ctx, inv.ID); err != nil { return err }
+	var inviter int64
+	if inv.InviterID != nil { inviter = *inv.InviterID }
+	return tx.Groups.RecordMembershipEvent(ctx, g.ID, userID, inviter, store.EventJoined, nil)
+}
@@ -2,18 +2,22 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
-	"strconv"
+	"fmt"
+	"strings"
+	"time"
 
 	"secure-messaging-backend/internal/store"
 )
 
 type JoinRequestService struct {
+	uow    *store.UnitOfWork
 	groups *store.GroupStore
 }
 
-func NewJoinRequestService(groups *store.GroupStore) *JoinRequestService {
-	return &JoinRequestService{groups: groups}
+func NewJoinRequestService(uow *store.UnitOfWork, groups *store.GroupStore) *JoinRequestService {
+	return &JoinRequestService{uow: uow, groups: groups}
 }
 
 func (s *JoinRequestService) ListPending(ctx context.Context, groupID, ownerID int64) ([]store.JoinRequest, error) {
@@ -23,22 +27,211 @@ func (s *JoinRequestService) ListPending(ctx context.Context, groupID, ownerID i
 	return s.groups.ListPendingJoinRequests(ctx, groupID)
 }
 
-func (s *JoinRequestService) Approve(ctx context.Context, groupID, ownerID, reqID int64) error {
-	g, err := s.groups.GetGroup(ctx, groupID)
-	if err != nil { return err }
-	if g.OwnerID != ownerID { return errors.New("only owner can approve") }
-	jr, err := s.groups.GetJoinRequestByID(ctx, reqID)
-	if err != nil { return err }
-	if jr.GroupID != groupID { return errors.New("request not in this group: " + strconv.FormatInt(jr.GroupID, 10)) }
-	return s.groups.ApproveJoinRequest(ctx, reqID)
+// ErrJoinRequestNotFound is returned for unknown request ids and for
+// requests that belong to someone else.
+var ErrJoinRequestNotFound = errors.New("join request not found")
+
+// lockJoinRequest locks the request, mapping a missing row to
+// ErrJoinRequestNotFound.
+func lockJoinRequest(ctx context.Context, tx *store.Tx, reqID int64) (*store.JoinRequest, error) {
+	jr, err := tx.Groups.LockJoinRequest(ctx, reqID)
+	if errors.Is(err, sql.ErrNoRows) { return nil, ErrJoinRequestNotFound }
+	return jr, err
 }
 
-func (s *JoinRequestService) Decline(ctx context.Context, groupID, ownerID, reqID int64) error {
-	g, err := s.groups.GetGroup(ctx, groupID)
-	if err != nil { return err }
-	if g.OwnerID != ownerID { return errors.New("only owner can decline") }
-	jr, err := s.groups.GetJoinRequestByID(ctx, reqID)
-	if err != nil { return err }
-	if jr.GroupID != groupID { return errors.New("request not in this group") }
-	return s.groups.SetJoinRequestStatus(ctx, reqID, "declined")
+// checkPending validates that jr belongs to groupID and can still be decided.
+// A request of another group is reported as not found, so ids don't reveal
+// which group they belong to.
+func checkPending(jr *store.JoinRequest, groupID int64) error {
+	if jr.GroupID != groupID { return ErrJoinRequestNotFound }
+	if jr.Status != store.JoinPending { return errors.New("join request is " + jr.Status) }
+	if !jr.ExpiresAt.After(time.Now()) { return errors.New("join request has expired") }
+	return nil
+}
+
+// Approve admits the requester under a lock on the group row, re-checking
+// bans and capacity at approval time.
+func (s *JoinRequestService) Approve(ctx context.Context, groupID, ownerID, reqID int64, reason *string) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		g, err := tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		if g.OwnerID != ownerID { return errors.New("only owner can approve") }
+		if g.Archived() { return ErrGroupArchived }
+		jr, err := lockJoinRequest(ctx, tx, reqID)
+		if err != nil { return err }
+		if err := checkPending(jr, groupID); err != nil { return err }
+		if banned, err := tx.Groups.IsBanned(ctx, groupID, jr.RequesterID); err != nil { return err } else if banned { return errors.New("requester is banned") }
+		count, err := tx.Groups.CountMembers(ctx, groupID)
+		if err != nil { return err }
+		added, err := admitRequester(ctx, tx, g, count, ownerID, jr, reason)
+		if err != nil { return err }
+		if !added { return errors.New("group full") }
+		return nil
+	})
+}
+
+// admitRequester adds jr's requester as a member, unless already one, and
+// marks jr approved. It returns false without changes when the group already
+// has count >= max_members members. The caller holds the group lock.
+func admitRequester(ctx context.Context, tx *store.Tx, g *store.Group, count int, ownerID int64, jr *store.JoinRequest, reason *string) (bool, error) {
+	isMember, err := tx.Groups.IsMember(ctx, g.ID, jr.RequesterID)
+	if err != nil { return false, err }
+	if !isMember {
+		if count >= g.MaxMembers { return false, nil }
+		if err := tx.Groups.AddMember(ctx, g.ID, jr.RequesterID, store.RoleMember); err != nil { return false, err }
+		if err := tx.Groups.RecordMembershipEvent(ctx, g.ID, jr.RequesterID, ownerID, store.EventApproved, nil); err != nil { return false, err }
+	}
+	if err := tx.Groups.DecideJoinRequest(ctx, jr.ID, store.JoinApproved, ownerID, reason); err != nil { return false, err }
+	return true, recordJoinDecision(ctx, tx, jr, ownerID, store.JoinApproved, reason)
+}
+
+// recordJoinDecision writes the audit log entry for deciding jr.
+func recordJoinDecision(ctx context.Context, tx *store.Tx, jr *store.JoinRequest, ownerID int64, status string, reason *string) error {
+	action := store.AuditJoinApproved
+	if status == store.JoinDeclined { action = store.AuditJoinDeclined }
+	return tx.Groups.RecordAudit(ctx, store.AuditRecord{
+		GroupID: jr.GroupID, ActorID: ownerID, Action: action, TargetUserID: jr.RequesterID, TargetID: jr.ID, Reason: reason,
+		Before: map[string]string{"status": jr.Status}, After: map[string]string{"status": status},
+	})
+}
+
+func (s *JoinRequestService) Decline(ctx context.Context, groupID, ownerID, reqID int64, reason *string) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		g, err := tx.Groups.GetGroup(ctx, groupID)
+		if err != nil { return err }
+		if g.OwnerID != ownerID { return errors.New("only owner can decline") }
+		jr, err := lockJoinRequest(ctx, tx, reqID)
+		if err != nil { return err }
+		if err := checkPending(jr, groupID); err != nil { return err }
+		if err := tx.Groups.DecideJoinRequest(ctx, reqID, store.JoinDeclined, ownerID, reason); err != nil { return err }
+		return recordJoinDecision(ctx, tx, jr, ownerID, store.JoinDeclined, reason)
+	})
+}
+
+const maxBulkJoinRequests = 500
+
+// Per-item outcomes of a bulk decision.
+const (
+	BulkApproved   = "approved"
+	BulkDeclined   = "declined"
+	BulkGroupFull  = "group_full"
+	BulkBanned     = "banned"
+	BulkNotPending = "not_pending"
+	BulkExpired    = "expired"
+	BulkNotFound   = "not_found"
+)
+
+type BulkDecisionInput struct {
+	GroupID int64
+	OwnerID int64
+	Approve bool
+	Reason  *string
+	// Either explicit request ids, or a filter over all pending requests.
+	IDs    []int64
+	Filter store.JoinRequestFilter
+}
+
+type BulkOutcome struct {
+	RequestID int64
+	Outcome   string
+}
+
+// BulkDecide approves or declines many requests in one transaction, oldest
+// first. Approvals stop once the group reaches max_members; the remaining
+// requests stay pending and are reported as group_full.
+func (s *JoinRequestService) BulkDecide(ctx context.Context, in BulkDecisionInput) ([]BulkOutcome, error) {
+	if len(in.IDs) > maxBulkJoinRequests { return nil, fmt.Errorf("at most %d requests per call", maxBulkJoinRequests) }
+	in.Filter.IDs = in.IDs
+	in.Filter.EmailDomain = strings.ToLower(strings.TrimSpace(in.Filter.EmailDomain))
+	var out []BulkOutcome
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		out = nil
+		g, err := tx.Groups.LockGroup(ctx, in.GroupID)
+		if err != nil { return err }
+		if g.OwnerID != in.OwnerID { return errors.New("only owner can decide join requests") }
+		if in.Approve && g.Archived() { return ErrGroupArchived }
+		rows, err := tx.Groups.LockJoinRequests(ctx, in.GroupID, in.Filter, maxBulkJoinRequests)
+		if err != nil { return err }
+		count, err := tx.Groups.CountMembers(ctx, in.GroupID)
+		if err != nil { return err }
+		seen := map[int64]bool{}
+		now := time.Now()
+		for i := range rows {
+			jr := &rows[i]
+			seen[jr.ID] = true
+			res := BulkOutcome{RequestID: jr.ID}
+			switch {
+			case jr.Status != store.JoinPending:
+				res.Outcome = BulkNotPending
+			case !jr.ExpiresAt.After(now):
+				res.Outcome = BulkExpired
+			case !in.Approve:
+				if err := tx.Groups.DecideJoinRequest(ctx, jr.ID, store.JoinDeclined, in.OwnerID, in.Reason); err != nil { return err }
+				if err := recordJoinDecision(ctx, tx, jr, in.OwnerID, store.JoinDeclined, in.Reason); err != nil { return err }
+				res.Outcome = BulkDeclined
+			default:
+				banned, err := tx.Groups.IsBanned(ctx, in.GroupID, jr.RequesterID)
+				if err != nil { return err }
+				if banned { res.Outcome = BulkBanned; break }
+				added, err := admitRequester(ctx, tx, g, count, in.OwnerID, jr, in.Reason)
+				if err != nil { return err }
+				if !added { res.Outcome = BulkGroupFull; break }
+				count, err = tx.Groups.CountMembers(ctx, in.GroupID)
+				if err != nil { return err }
+				res.Outcome = BulkApproved
+			}
+			out = append(out, res)
+		}
+		for _, id := range in.IDs {
+			if !seen[id] {
+				seen[id] = true
+				out = append(out, BulkOutcome{RequestID: id, Outcome: BulkNotFound})
+			}
+		}
+		return nil
+	})
+	if err != nil { return nil, err }
+	return out, nil
+}
+
+// Cancel withdraws the requester's own pending request.
+func (s *JoinRequestService) Cancel(ctx context.Context, requesterID, reqID int64) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		jr, err := lockJoinRequest(ctx, tx, reqID)
+		if err != nil { return err }
+		if jr.RequesterID != requesterID { return ErrJoinRequestNotFound }
+		if jr.Status != store.JoinPending { return errors.New("join request is " + jr.Status) }
+		return tx.Groups.DecideJoinRequest(ctx, reqID, store.JoinCancelled, 0, nil)
+	})
+}
+
+type UserJoinRequestPage struct {
+	Requests   []store.UserJoinRequest
+	NextCursor string
+}
+
+// ListMine pages the caller's own join requests, newest first.
+func (s *JoinRequestService) ListMine(ctx context.Context, userID int64, status, cursor string, limit int) (*UserJoinRequestPage, error) {
+	switch status {
+	case "", store.JoinPending, store.JoinApproved, store.JoinDeclined, store.JoinCancelled, store.JoinExpired:
+	default:
+		return nil, errors.New("unknown status " + status)
+	}
+	after, err := store.DecodeCursor(cursor)
+	if err != nil { return nil, err }
+	if limit <= 0 || limit > 100 { limit = 50 }
+	rows, err := s.groups.ListUserJoinRequests(ctx, userID, status, after, limit+1)
+	if err != nil { return nil, err }
+	page := &UserJoinRequestPage{Requests: rows}
+	if len(rows) > limit {
+		page.Requests = rows[:limit]
+		page.NextCursor = store.Cursor{ID: page.Requests[limit-1].ID}.Encode()
+	}
+	return page, nil
+}
+
+// ExpirePending is run periodically to expire lapsed pending requests.
+func (s *JoinRequestService) ExpirePending(ctx context.Context) error {
+	_, err := s.groups.ExpireJoinRequests(ctx)
+	return err
 }
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestJoinRequestIDsDoNotLeakAcrossGroups(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	users := e.createUsers(t, 3)
+	ownerA, ownerB, requester := users[0], users[1], users[2]
+	a := e.createGroup(t, ownerA, "secret", 10, false)
+	b := e.createGroup(t, ownerB, "private", 10, false)
+	status, err := e.groups.Join(ctx, JoinInput{GroupID: b.ID, UserID: ownerA})
+	if err != nil { t.Fatal(err) }
+	reqID, err := strconv.ParseInt(strings.TrimPrefix(status, "join_requested:"), 10, 64)
+	if err != nil { t.Fatalf("join status %q", status) }
+
+	// The owner of another group sees the same answer as for an id that
+	// doesn't exist at all.
+	for _, id := range []int64{reqID, reqID + 1000} {
+		if err := e.requests.Approve(ctx, a.ID, ownerA, id, nil); !errors.Is(err, ErrJoinRequestNotFound) { t.Errorf("approve %d: err = %v", id, err) }
+		if err := e.requests.Decline(ctx, a.ID, ownerA, id, nil); !errors.Is(err, ErrJoinRequestNotFound) { t.Errorf("decline %d: err = %v", id, err) }
+		if err := e.requests.Cancel(ctx, requester, id); !errors.Is(err, ErrJoinRequestNotFound) { t.Errorf("cancel %d: err = %v", id, err) }
+	}
+	if err := e.requests.Approve(ctx, b.ID, ownerB, reqID, nil); err != nil { t.Errorf("approve in own group: %v", err) }
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestListMembersErrors(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	users := e.createUsers(t, 2)
+	owner, outsider := users[0], users[1]
+	g := e.createGroup(t, owner, "private", 10, false)
+
+	cases := []struct {
+		name string
+		in   ListMembersInput
+		want error
+	}{
+		{"missing group", ListMembersInput{GroupID: g.ID + 1000, RequesterID: owner}, ErrGroupNotFound},
+		{"hidden from non-members", ListMembersInput{GroupID: g.ID, RequesterID: outsider}, ErrMembersHidden},
+		{"unknown role", ListMembersInput{GroupID: g.ID, RequesterID: owner, Role: "boss"}, ErrInvalidRole},
+	}
+	for _, tc := range cases {
+		if _, err := e.groups.ListMembers(ctx, tc.in); !errors.Is(err, tc.want) { t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want) }
+	}
+	if page, err := e.groups.ListMembers(ctx, ListMembersInput{GroupID: g.ID, RequesterID: owner}); err != nil || page.Total != 1 { t.Errorf("owner listing: %+v, %v", page, err) }
+}
@@ -8,31 +8,40 @@ import (
 	"github.com/rs/zerolog"
 	"secure-messaging-backend/internal/config"
 	appcrypto "secure-messaging-backend/internal/crypto"
+	"secure-messaging-backend/internal/notify"
 	"secure-messaging-backend/internal/store"
 )
 
 type MessageService struct {
-	cfg    *config.Config
-	groups *store.GroupStore
-	msgs   *store.MessageStore
-	log    zerolog.Logger
+	cfg      *config.Config
+	uow      *store.UnitOfWork
+	groups   *store.GroupStore
+	msgs     *store.MessageStore
+	notifier notify.Notifier
+	log      zerolog.Logger
+	automod  *automodCache
 }
 
-func NewMessageService(cfg *config.Config, groups *store.GroupStore, msgs *store.MessageStore, log zerolog.Logger) *MessageService {
-	return &MessageService{cfg: cfg, groups: groups, msgs: msgs, log: log}
+func NewMessageService(cfg *config.Config, uow *store.UnitOfWork, groups *store.GroupStore, msgs *store.MessageStore, notifier notify.Notifier, log zerolog.Logger) *MessageService {
+	return &MessageService{cfg: cfg, uow: uow, groups: groups, msgs: msgs, notifier: notifier, log: log, automod: newAutomodCache(automodCacheSize)}
 }
 
+// SendMessageInput targets a channel of the group; ChannelID 0 means the
+// group's default channel.
 type SendMessageInput struct {
-	GroupID  int64
-	SenderID int64
-	Plain    []byte
+	GroupID   int64
+	ChannelID int64
+	SenderID  int64
+	Plain     []byte
 }
 
 type MessageDTO struct {
 	ID        int64
 	GroupID   int64
+	ChannelID int64
 	SenderID  int64
 	Plain     []byte
+	Status    string
 	CreatedAt time.Time
 }
 
@@ -43,36 +52,117 @@ func (s *MessageService) ensureMember(ctx context.Context, groupID, userID int64
 	return nil
 }
 
-func (s *MessageService) unwrapKey(ctx context.Context, groupID int64) ([]byte, error) {
-	g, err := s.groups.GetGroup(ctx, groupID)
-	if err != nil { return nil, err }
+// channelFor resolves the channel a member reads or posts in, and the
+// member's role; channelID 0 is the group's default channel.
+func (s *MessageService) channelFor(ctx context.Context, groupID, channelID, userID int64) (*store.Channel, string, error) {
+	role, err := s.groups.GetMemberRole(ctx, groupID, userID)
+	if err != nil { return nil, "", err }
+	if role == "" { return nil, "", errors.New("not a group member") }
+	var ch *store.Channel
+	if channelID == 0 {
+		ch, err = s.groups.DefaultChannel(ctx, groupID)
+	} else {
+		ch, err = s.groups.GetChannel(ctx, groupID, channelID)
+	}
+	if err != nil { return nil, "", err }
+	if ch == nil { return nil, "", ErrChannelNotFound }
+	ok, err := s.groups.CanSeeChannel(ctx, ch, userID, role)
+	if err != nil { return nil, "", err }
+	if !ok { return nil, "", ErrChannelNotFound }
+	return ch, role, nil
+}
+
+func (s *MessageService) groupKey(g *store.Group) ([]byte, error) {
 	return appcrypto.UnwrapKey([]byte(s.cfg.MasterKey), g.EncryptedKey, g.KeyNonce)
 }
 
 func (s *MessageService) Send(ctx context.Context, in SendMessageInput) (*MessageDTO, error) {
-	if err := s.ensureMember(ctx, in.GroupID, in.SenderID); err != nil { return nil, err }
-	key, err := s.unwrapKey(ctx, in.GroupID)
+	ch, _, err := s.channelFor(ctx, in.GroupID, in.ChannelID, in.SenderID)
+	if err != nil { return nil, err }
+	g, err := s.groups.GetGroup(ctx, in.GroupID)
+	if err != nil { return nil, err }
+	if g.Archived() { return nil, ErrGroupArchived }
+	if ch.Archived() { return nil, ErrChannelArchived }
+	member, err := s.groups.GetMember(ctx, in.GroupID, in.SenderID)
+	if err != nil { return nil, err }
+	if member == nil { return nil, errors.New("not a group member") }
+	if g.AnnouncementOnly && !isModerator(member.Role) { return nil, ErrAnnouncementOnly }
+	if member.MutedAt(time.Now()) { return nil, &MutedError{Until: *member.MutedUntil, Reason: member.MuteReason} }
+	pending, err := s.groups.PendingRulesVersion(ctx, in.GroupID, in.SenderID)
+	if err != nil { return nil, err }
+	if pending != 0 { return nil, &RulesAcceptanceError{Version: pending} }
+	status := store.MessageApproved
+	var verdict AutomodVerdict
+	if !isModerator(member.Role) {
+		hold, err := s.shouldHold(ctx, g, member)
+		if err != nil { return nil, err }
+		if hold { status = store.MessageHeld }
+		verdict, err = s.runAutomod(ctx, in.GroupID, string(in.Plain))
+		if err != nil { return nil, err }
+		switch verdict.Action {
+		case store.AutomodReject:
+			// Nothing is stored, so the rejection is the rules' whole effect.
+			if err := s.groups.RecordAutomodHits(ctx, verdict.RuleIDs); err != nil { return nil, err }
+			return nil, ErrAutomodRejected
+		case store.AutomodHold:
+			status = store.MessageHeld
+		}
+	}
+	key, err := s.groupKey(g)
 	if err != nil { return nil, err }
 	ct, iv, err := appcrypto.EncryptMessage(key, in.Plain)
 	if err != nil { return nil, err }
-	m, err := s.msgs.Create(ctx, in.GroupID, in.SenderID, ct, iv)
+	// The send slot is taken with the insert, so only stored messages count
+	// against slow mode and the burst limit, and only they count rule hits.
+	var m *store.Message
+	err = s.uow.Do(ctx, func(tx *store.Tx) error {
+		if !isModerator(member.Role) {
+			wait, err := tx.Messages.TakeSendSlot(ctx, in.GroupID, in.SenderID, store.SendLimits{
+				SlowMode:    time.Duration(g.SlowModeSeconds) * time.Second,
+				BurstLimit:  s.cfg.MessageBurstLimit,
+				BurstWindow: time.Duration(s.cfg.MessageBurstSeconds) * time.Second,
+			})
+			if err != nil { return err }
+			if wait > 0 { return &RateLimitedError{RetryAfter: wait} }
+		}
+		var err error
+		m, err = tx.Messages.Create(ctx, in.GroupID, ch.ID, in.SenderID, ct, iv, status)
+		if err != nil { return err }
+		return tx.Groups.RecordAutomodHits(ctx, verdict.RuleIDs)
+	})
 	if err != nil { return nil, err }
+	if verdict.Action == store.AutomodFlag { s.flagMessage(ctx, g, m, verdict.RuleIDs) }
 	// Simulated notification via log
-	s.log.Info().Int64("group_id", in.GroupID).Int64("sender_id", in.SenderID).Int64("message_id", m.ID).Msg("Message sent")
-	return &MessageDTO{ID: m.ID, GroupID: m.GroupID, SenderID: m.SenderID, Plain: in.Plain, CreatedAt: m.CreatedAt}, nil
+	s.log.Info().Int64("group_id", in.GroupID).Int64("channel_id", ch.ID).Int64("sender_id", in.SenderID).Int64("message_id", m.ID).Str("status", m.Status).Msg("Message sent")
+	return &MessageDTO{ID: m.ID, GroupID: m.GroupID, ChannelID: m.ChannelID, SenderID: m.SenderID, Plain: in.Plain, Status: m.Status, CreatedAt: m.CreatedAt}, nil
 }
 
-func (s *MessageService) List(ctx context.Context, groupID, requesterID int64, limit int, before *time.Time) ([]MessageDTO, error) {
-	if err := s.ensureMember(ctx, groupID, requesterID); err != nil { return nil, err }
-	key, err := s.unwrapKey(ctx, groupID)
+// List pages a channel's messages newest first; channelID 0 is the default
+// channel.
+func (s *MessageService) List(ctx context.Context, groupID, channelID, requesterID int64, limit int, before *time.Time) ([]MessageDTO, error) {
+	ch, role, err := s.channelFor(ctx, groupID, channelID, requesterID)
+	if err != nil { return nil, err }
+	g, err := s.groups.GetGroup(ctx, groupID)
 	if err != nil { return nil, err }
-	rows, err := s.msgs.List(ctx, groupID, limit, before)
+	rows, err := s.msgs.List(ctx, ch.ID, requesterID, isModerator(role), limit, before)
+	if err != nil { return nil, err }
+	return s.decrypt(g, rows)
+}
+
+func (s *MessageService) decrypt(g *store.Group, rows []store.Message) ([]MessageDTO, error) {
+	key, err := s.groupKey(g)
 	if err != nil { return nil, err }
 	out := make([]MessageDTO, 0, len(rows))
 	for _, r := range rows {
 		pt, err := appcrypto.DecryptMessage(key, r.Ciphertext, r.IV)
 		if err != nil { return nil, err }
-		out = append(out, MessageDTO{ID: r.ID, GroupID: r.GroupID, SenderID: r.SenderID, Plain: pt, CreatedAt: r.CreatedAt})
+		out = append(out, MessageDTO{ID: r.ID, GroupID: r.GroupID, ChannelID: r.ChannelID, SenderID: r.SenderID, Plain: pt, Status: r.Status, CreatedAt: r.CreatedAt})
 	}
 	return out, nil
 }
+
+// MarkRead moves the caller's read marker to messageID (0 = latest message).
+func (s *MessageService) MarkRead(ctx context.Context, groupID, userID, messageID int64) error {
+	if err := s.ensureMember(ctx, groupID, userID); err != nil { return err }
+	return s.groups.MarkRead(ctx, groupID, userID, messageID)
+}
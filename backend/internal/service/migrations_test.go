@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// migrateDown runs the down migrations newest first, through the one whose
+// file name starts with to.
+func (e *testEnv) migrateDown(t *testing.T, to string) {
+	t.Helper()
+	files, err := filepath.Glob("../../migrations/*.down.sql")
+	if err != nil { t.Fatal(err) }
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	for _, f := range files {
+		if filepath.Base(f) < to { break }
+		b, err := os.ReadFile(f)
+		if err != nil { t.Fatal(err) }
+		if _, err := e.db.Exec(string(b)); err != nil { t.Fatalf("%s: %v", filepath.Base(f), err) }
+	}
+}
+
+func TestSecretGroupsRollBackToPrivate(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	users := e.createUsers(t, 2)
+	g := e.createGroup(t, users[0], "secret", 10, false)
+	if _, err := e.groups.AcceptInvite(ctx, users[1], e.inviteUser(t, g.ID, users[0], users[1])); err != nil { t.Fatal(err) }
+
+	e.migrateDown(t, "0014")
+	var typ string
+	if err := e.db.GetContext(ctx, &typ, `SELECT type FROM groups WHERE id=$1`, g.ID); err != nil { t.Fatalf("secret group lost on rollback: %v", err) }
+	if typ != "private" { t.Errorf("type = %q, want private", typ) }
+	if got := e.memberCount(t, g.ID); got != 2 { t.Errorf("members = %d, want 2", got) }
+}
+
+func (e *testEnv) inviteUser(t *testing.T, groupID, inviter, invitee int64) int64 {
+	t.Helper()
+	inv, err := e.groups.InviteUser(context.Background(), groupID, inviter, invitee)
+	if err != nil { t.Fatal(err) }
+	return inv.ID
+}
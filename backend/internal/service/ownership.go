@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"secure-messaging-backend/internal/notify"
+	"secure-messaging-backend/internal/store"
+)
+
+// TransferOwner offers ownership of the group to a member. Nothing changes
+// until they accept; an earlier pending offer is cancelled.
+func (s *GroupService) TransferOwner(ctx context.Context, groupID, currentOwner, newOwner int64) (*store.OwnershipTransfer, error) {
+	var t *store.OwnershipTransfer
+	var g *store.Group
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		var err error
+		g, err = tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		if g.OwnerID != currentOwner { return errors.New("only owner can transfer") }
+		if newOwner == currentOwner { return errors.New("already the owner") }
+		isMember, err := tx.Groups.IsMember(ctx, groupID, newOwner)
+		if err != nil { return err }
+		if !isMember { return errors.New("new owner must be a member") }
+		if err := tx.Groups.ExpireLapsedOwnershipTransfer(ctx, groupID); err != nil { return err }
+		prev, err := tx.Groups.CancelPendingOwnershipTransfer(ctx, groupID)
+		if err != nil { return err }
+		if prev != nil {
+			if err := tx.Groups.RecordOwnershipTransferEvent(ctx, prev, currentOwner, store.TransferCancelled); err != nil { return err }
+			if err := recordTransferAudit(ctx, tx, prev, currentOwner, store.AuditOwnershipCancelled); err != nil { return err }
+		}
+		ttl := time.Duration(s.cfg.OwnerTransferTTLHours) * time.Hour
+		t, err = tx.Groups.CreateOwnershipTransfer(ctx, groupID, currentOwner, newOwner, time.Now().Add(ttl))
+		if err != nil { return err }
+		if err := tx.Groups.RecordOwnershipTransferEvent(ctx, t, currentOwner, store.TransferOffered); err != nil { return err }
+		return recordTransferAudit(ctx, tx, t, currentOwner, store.AuditOwnershipOffered)
+	})
+	if err != nil { return nil, err }
+	_ = s.notifier.SendToUsers(ctx, []int64{newOwner}, notify.Payload{
+		Type:  "ownership_offered",
+		Title: "Ownership offered",
+		Body:  "You have been asked to take over " + g.Name + ".",
+		Data:  map[string]string{"group_id": strconv.FormatInt(groupID, 10), "transfer_id": strconv.FormatInt(t.ID, 10)},
+	})
+	return t, nil
+}
+
+// recordTransferAudit writes the audit log entry for a step of transfer t.
+func recordTransferAudit(ctx context.Context, tx *store.Tx, t *store.OwnershipTransfer, actorID int64, action string) error {
+	return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: t.GroupID, ActorID: actorID, Action: action, TargetUserID: t.ToUserID, TargetID: t.ID})
+}
+
+// lockPendingTransfer returns the group, locked, and its live pending
+// transfer.
+func lockPendingTransfer(ctx context.Context, tx *store.Tx, groupID int64) (*store.Group, *store.OwnershipTransfer, error) {
+	g, err := tx.Groups.LockGroup(ctx, groupID)
+	if err != nil { return nil, nil, err }
+	if err := tx.Groups.ExpireLapsedOwnershipTransfer(ctx, groupID); err != nil { return nil, nil, err }
+	t, err := tx.Groups.GetPendingOwnershipTransfer(ctx, groupID)
+	if err != nil { return nil, nil, err }
+	if t == nil { return nil, nil, errors.New("no pending ownership transfer") }
+	return g, t, nil
+}
+
+// AcceptOwnership completes the pending transfer offered to userID. The
+// previous owner stays on as an admin.
+func (s *GroupService) AcceptOwnership(ctx context.Context, groupID, userID int64) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		g, t, err := lockPendingTransfer(ctx, tx, groupID)
+		if err != nil { return err }
+		if t.ToUserID != userID { return errors.New("transfer was not offered to you") }
+		if t.FromUserID == nil || *t.FromUserID != g.OwnerID { return errors.New("transfer is stale; the group has a new owner") }
+		isMember, err := tx.Groups.IsMember(ctx, groupID, userID)
+		if err != nil { return err }
+		if !isMember { return errors.New("new owner must be a member") }
+		if err := tx.Groups.TransferOwner(ctx, groupID, userID); err != nil { return err }
+		if err := tx.Groups.DecideOwnershipTransfer(ctx, t.ID, store.TransferAccepted); err != nil { return err }
+		if err := tx.Groups.RecordOwnershipTransferEvent(ctx, t, userID, store.TransferAccepted); err != nil { return err }
+		if err := tx.Groups.RecordAudit(ctx, store.AuditRecord{
+			GroupID: groupID, ActorID: userID, Action: store.AuditOwnershipAccepted, TargetUserID: userID, TargetID: t.ID,
+			Before: map[string]int64{"owner_id": g.OwnerID}, After: map[string]int64{"owner_id": userID},
+		}); err != nil { return err }
+		return recordRoleChanges(ctx, tx, groupID, userID, map[int64]string{g.OwnerID: store.RoleAdmin, userID: store.RoleOwner})
+	})
+}
+
+// DeclineOwnership turns down the pending transfer offered to userID.
+func (s *GroupService) DeclineOwnership(ctx context.Context, groupID, userID int64) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		_, t, err := lockPendingTransfer(ctx, tx, groupID)
+		if err != nil { return err }
+		if t.ToUserID != userID { return errors.New("transfer was not offered to you") }
+		if err := tx.Groups.DecideOwnershipTransfer(ctx, t.ID, store.TransferDeclined); err != nil { return err }
+		if err := tx.Groups.RecordOwnershipTransferEvent(ctx, t, userID, store.TransferDeclined); err != nil { return err }
+		return recordTransferAudit(ctx, tx, t, userID, store.AuditOwnershipDeclined)
+	})
+}
+
+// CancelOwnershipTransfer withdraws the owner's pending offer.
+func (s *GroupService) CancelOwnershipTransfer(ctx context.Context, groupID, ownerID int64) error {
+	return s.uow.Do(ctx, func(tx *store.Tx) error {
+		g, t, err := lockPendingTransfer(ctx, tx, groupID)
+		if err != nil { return err }
+		if g.OwnerID != ownerID { return errors.New("only owner can cancel a transfer") }
+		if err := tx.Groups.DecideOwnershipTransfer(ctx, t.ID, store.TransferCancelled); err != nil { return err }
+		if err := tx.Groups.RecordOwnershipTransferEvent(ctx, t, ownerID, store.TransferCancelled); err != nil { return err }
+		return recordTransferAudit(ctx, tx, t, ownerID, store.AuditOwnershipCancelled)
+	})
+}
+
+// PendingOwnershipTransfer returns the group's live offer to the owner, the
+// invitee, or an admin; nil if there is none.
+func (s *GroupService) PendingOwnershipTransfer(ctx context.Context, groupID, userID int64) (*store.OwnershipTransfer, error) {
+	role, err := s.groups.GetMemberRole(ctx, groupID, userID)
+	if err != nil { return nil, err }
+	if role == "" { return nil, errors.New("not a member") }
+	t, err := s.groups.GetPendingOwnershipTransfer(ctx, groupID)
+	if err != nil || t == nil { return nil, err }
+	if !isModerator(role) && t.ToUserID != userID { return nil, nil }
+	return t, nil
+}
+
+type OwnershipTransferHistory struct {
+	Events     []store.OwnershipTransferEvent
+	NextCursor string
+}
+
+// OwnershipTransferHistory pages the group's transfer audit trail, newest
+// first, for owners and admins.
+func (s *GroupService) OwnershipTransferHistory(ctx context.Context, groupID, requesterID int64, cursor string, limit int) (*OwnershipTransferHistory, error) {
+	role, err := s.groups.GetMemberRole(ctx, groupID, requesterID)
+	if err != nil { return nil, err }
+	if !isModerator(role) { return nil, errors.New("only owner or admins can view transfer history") }
+	after, err := store.DecodeCursor(cursor)
+	if err != nil { return nil, err }
+	if limit <= 0 || limit > 100 { limit = 50 }
+	rows, err := s.groups.ListOwnershipTransferEvents(ctx, groupID, after, limit+1)
+	if err != nil { return nil, err }
+	page := &OwnershipTransferHistory{Events: rows}
+	if len(rows) > limit {
+		page.Events = rows[:limit]
+		page.NextCursor = store.Cursor{ID: page.Events[limit-1].ID}.Encode()
+	}
+	return page, nil
+}
+
+// ExpireOwnershipTransfers is run periodically to lapse unanswered offers.
+func (s *GroupService) ExpireOwnershipTransfers(ctx context.Context) error {
+	_, err := s.groups.ExpireOwnershipTransfers(ctx)
+	return err
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"secure-messaging-backend/internal/store"
+)
+
+func (s *GroupService) restoreWindow() time.Duration {
+	return time.Duration(s.cfg.GroupRestoreDays) * 24 * time.Hour
+}
+
+// Restore undoes a delete; only the former owner, and only within the
+// restore window.
+func (s *GroupService) Restore(ctx context.Context, groupID, ownerID int64) (*store.Group, error) {
+	var g *store.Group
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		var err error
+		g, err = tx.Groups.LockDeletedGroup(ctx, groupID)
+		if errors.Is(err, sql.ErrNoRows) { return errors.New("group is not deleted") }
+		if err != nil { return err }
+		if g.OwnerID != ownerID { return errors.New("only the former owner can restore") }
+		if time.Since(*g.DeletedAt) > s.restoreWindow() { return errors.New("restore window has passed") }
+		if err := tx.Groups.RestoreGroup(ctx, groupID); err != nil { return err }
+		g.DeletedAt = nil
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: ownerID, Action: store.AuditGroupRestored})
+	})
+	if err != nil { return nil, err }
+	return g, nil
+}
+
+// PurgeDeleted is run periodically to hard-delete groups whose restore
+// window has passed, leaving an audit record for each.
+func (s *GroupService) PurgeDeleted(ctx context.Context) error {
+	ids, err := s.groups.ListPurgeableGroups(ctx, time.Now().Add(-s.restoreWindow()), 100)
+	if err != nil { return err }
+	for _, id := range ids {
+		err := s.uow.Do(ctx, func(tx *store.Tx) error {
+			g, err := tx.Groups.LockDeletedGroup(ctx, id)
+			if errors.Is(err, sql.ErrNoRows) { return nil }
+			if err != nil { return err }
+			if time.Since(*g.DeletedAt) <= s.restoreWindow() { return nil }
+			_, err = tx.Groups.PurgeGroup(ctx, g)
+			return err
+		})
+		if err != nil { return err }
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"secure-messaging-backend/internal/store"
+)
+
+const (
+	maxQuestions     = 10
+	maxPromptLength  = 500
+	maxAnswerLength  = 2000
+	maxChoiceOptions = 20
+)
+
+type QuestionInput struct {
+	Prompt   string
+	Kind     string
+	Options  []string
+	Required bool
+}
+
+type AnswerInput struct {
+	QuestionID int64
+	Answer     string
+}
+
+// ListQuestions returns the group's screening questions in display order.
+func (s *GroupService) ListQuestions(ctx context.Context, groupID int64) ([]store.Question, error) {
+	if _, err := s.groups.GetGroup(ctx, groupID); err != nil { return nil, err }
+	return s.groups.ListQuestions(ctx, groupID)
+}
+
+// SetQuestions replaces the group's question set; owner only. Requests
+// already filed keep the answers and prompts they were submitted with.
+func (s *GroupService) SetQuestions(ctx context.Context, groupID, ownerID int64, in []QuestionInput) ([]store.Question, error) {
+	if len(in) > maxQuestions { return nil, fmt.Errorf("at most %d questions allowed", maxQuestions) }
+	qs := make([]store.Question, 0, len(in))
+	for i, q := range in {
+		q.Prompt = strings.TrimSpace(q.Prompt)
+		if q.Prompt == "" || len(q.Prompt) > maxPromptLength { return nil, fmt.Errorf("question %d: prompt must be 1-%d characters", i+1, maxPromptLength) }
+		switch q.Kind {
+		case store.QuestionText:
+			q.Options = nil
+		case store.QuestionChoice:
+			if len(q.Options) < 2 || len(q.Options) > maxChoiceOptions { return nil, fmt.Errorf("question %d: choice needs 2-%d options", i+1, maxChoiceOptions) }
+			for j, o := range q.Options {
+				q.Options[j] = strings.TrimSpace(o)
+				if q.Options[j] == "" { return nil, fmt.Errorf("question %d: options must not be empty", i+1) }
+			}
+		default:
+			return nil, fmt.Errorf("question %d: kind must be text or choice", i+1)
+		}
+		qs = append(qs, store.Question{Prompt: q.Prompt, Kind: q.Kind, Options: q.Options, Required: q.Required})
+	}
+	var out []store.Question
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		g, err := tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		if g.OwnerID != ownerID { return errors.New("only owner can manage questions") }
+		before, err := tx.Groups.ListQuestions(ctx, groupID)
+		if err != nil { return err }
+		out, err = tx.Groups.ReplaceQuestions(ctx, groupID, qs)
+		if err != nil { return err }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: groupID, ActorID: ownerID, Action: store.AuditQuestionsUpdated, Before: questionsAudit(before), After: questionsAudit(out)})
+	})
+	return out, err
+}
+
+// buildAnswers validates answers against the group's questions: every
+// required question answered, choices from the listed options, no unknown
+// question ids.
+func buildAnswers(questions []store.Question, answers []AnswerInput) (store.JoinAnswers, error) {
+	byID := map[int64]string{}
+	for _, a := range answers {
+		if _, dup := byID[a.QuestionID]; dup { return nil, fmt.Errorf("question %d answered twice", a.QuestionID) }
+		byID[a.QuestionID] = strings.TrimSpace(a.Answer)
+	}
+	out := store.JoinAnswers{}
+	for _, q := range questions {
+		ans, ok := byID[q.ID]
+		delete(byID, q.ID)
+		if !ok || ans == "" {
+			if q.Required { return nil, fmt.Errorf("answer required: %s", q.Prompt) }
+			continue
+		}
+		if len(ans) > maxAnswerLength { return nil, fmt.Errorf("answer too long: %s", q.Prompt) }
+		if q.Kind == store.QuestionChoice && !containsString(q.Options, ans) { return nil, fmt.Errorf("answer must be one of the options: %s", q.Prompt) }
+		out = append(out, store.JoinAnswer{QuestionID: q.ID, Prompt: q.Prompt, Answer: ans})
+	}
+	for id := range byID {
+		return nil, fmt.Errorf("unknown question %d", id)
+	}
+	return out, nil
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v { return true }
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"secure-messaging-backend/internal/notify"
+	"secure-messaging-backend/internal/store"
+)
+
+var ErrReportNotFound = errors.New("report not found")
+
+const maxReportComment = 1000
+
+var reportCategories = map[string]bool{
+	"spam": true, "harassment": true, "hate": true, "violence": true,
+	"sexual": true, "self_harm": true, "illegal": true, "other": true,
+}
+
+type ReportService struct {
+	uow      *store.UnitOfWork
+	reports  *store.ReportStore
+	groups   *store.GroupStore
+	users    *store.UserStore
+	msgs     *store.MessageStore
+	groupSvc *GroupService
+	notifier notify.Notifier
+}
+
+func NewReportService(uow *store.UnitOfWork, reports *store.ReportStore, groups *store.GroupStore, users *store.UserStore, msgs *store.MessageStore, groupSvc *GroupService, notifier notify.Notifier) *ReportService {
+	return &ReportService{uow: uow, reports: reports, groups: groups, users: users, msgs: msgs, groupSvc: groupSvc, notifier: notifier}
+}
+
+// ReportInput targets exactly one message, user or group. GroupID may
+// accompany a user report to say where the behaviour happened.
+type ReportInput struct {
+	ReporterID int64
+	TargetType string
+	MessageID  int64
+	UserID     int64
+	GroupID    int64
+	Category   string
+	Comment    string
+}
+
+// Create files a report. Reporters can only report what they can see.
+func (s *ReportService) Create(ctx context.Context, in ReportInput) (*store.Report, error) {
+	if !reportCategories[in.Category] { return nil, errors.New("invalid category") }
+	r := store.Report{ReporterID: in.ReporterID, TargetType: in.TargetType, Category: in.Category}
+	if c := strings.TrimSpace(in.Comment); c != "" {
+		if len(c) > maxReportComment { return nil, errors.New("comment too long") }
+		r.Comment = &c
+	}
+	switch in.TargetType {
+	case store.ReportMessage:
+		m, err := s.msgs.Get(ctx, in.MessageID)
+		if err != nil { return nil, err }
+		if m == nil { return nil, errors.New("message not found") }
+		if m.SenderID == in.ReporterID { return nil, errors.New("cannot report your own message") }
+		if ok, err := s.canSeeMessage(ctx, m, in.ReporterID); err != nil { return nil, err } else if !ok { return nil, errors.New("message not found") }
+		r.MessageID, r.UserID, r.GroupID = &m.ID, &m.SenderID, &m.GroupID
+	case store.ReportUser:
+		if in.UserID == in.ReporterID { return nil, errors.New("cannot report yourself") }
+		if _, err := s.users.GetUserByID(ctx, in.UserID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) { return nil, errors.New("user not found") }
+			return nil, err
+		}
+		r.UserID = &in.UserID
+		if in.GroupID != 0 {
+			isMember, err := s.groups.IsMember(ctx, in.GroupID, in.ReporterID)
+			if err != nil { return nil, err }
+			if !isMember { return nil, errors.New("you are not a member of that group") }
+			r.GroupID = &in.GroupID
+		}
+	case store.ReportGroup:
+		if _, err := s.groups.GetGroup(ctx, in.GroupID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) { return nil, ErrGroupNotFound }
+			return nil, err
+		}
+		if hidden, err := s.groups.HiddenFrom(ctx, in.GroupID, in.ReporterID); err != nil { return nil, err } else if hidden { return nil, ErrGroupNotFound }
+		r.GroupID = &in.GroupID
+	default:
+		return nil, errors.New("target_type must be message, user or group")
+	}
+	return s.reports.Create(ctx, r)
+}
+
+// canSeeMessage reports whether userID can read m in its channel.
+func (s *ReportService) canSeeMessage(ctx context.Context, m *store.Message, userID int64) (bool, error) {
+	role, err := s.groups.GetMemberRole(ctx, m.GroupID, userID)
+	if err != nil || role == "" { return false, err }
+	if m.Status != store.MessageApproved && !isModerator(role) { return false, nil }
+	ch, err := s.groups.GetChannel(ctx, m.GroupID, m.ChannelID)
+	if err != nil || ch == nil { return false, err }
+	return s.groups.CanSeeChannel(ctx, ch, userID, role)
+}
+
+type ReportPage struct {
+	Reports    []store.Report
+	NextCursor string
+}
+
+func (s *ReportService) list(ctx context.Context, f store.ReportFilter, cursor string, limit int) (*ReportPage, error) {
+	after, err := store.DecodeCursor(cursor)
+	if err != nil { return nil, err }
+	if limit <= 0 || limit > 100 { limit = 50 }
+	rows, err := s.reports.List(ctx, f, after, limit+1)
+	if err != nil { return nil, err }
+	page := &ReportPage{Reports: rows}
+	if len(rows) > limit {
+		page.Reports = rows[:limit]
+		page.NextCursor = store.Cursor{ID: page.Reports[limit-1].ID}.Encode()
+	}
+	return page, nil
+}
+
+// GroupQueue pages reports about messages and users in the group for its
+// owner and admins. Reports about the group itself go to platform admins.
+func (s *ReportService) GroupQueue(ctx context.Context, groupID, moderatorID int64, status, cursor string, limit int) (*ReportPage, error) {
+	if err := s.groupSvc.requireModerator(ctx, groupID, moderatorID, "review reports"); err != nil { return nil, err }
+	if status == "" { status = store.ReportOpen }
+	return s.list(ctx, store.ReportFilter{GroupID: groupID, Status: status, ContentOnly: true}, cursor, limit)
+}
+
+// AdminQueue pages all reports for platform admins.
+func (s *ReportService) AdminQueue(ctx context.Context, status, targetType, cursor string, limit int) (*ReportPage, error) {
+	if status == "" { status = store.ReportOpen }
+	return s.list(ctx, store.ReportFilter{Status: status, TargetType: targetType}, cursor, limit)
+}
+
+// Resolve closes a report with one of the resolution actions. Platform
+// admins can take any action. A group's owner and admins can dismiss
+// reports in their group and delete reported messages; banishing follows
+// Banish and is for the owner. Suspending accounts is for platform admins.
+func (s *ReportService) Resolve(ctx context.Context, reportID, resolverID int64, action string, note *string) error {
+	resolver, err := s.users.GetUserByID(ctx, resolverID)
+	if err != nil { return err }
+	var r *store.Report
+	var g *store.Group
+	var admitted []int64
+	err = s.uow.Do(ctx, func(tx *store.Tx) error {
+		var err error
+		if r, err = tx.Reports.Lock(ctx, reportID); err != nil { return err }
+		if r == nil { return ErrReportNotFound }
+		var role string
+		// A deleted group's report can still be resolved, without group actions.
+		if r.GroupID != nil {
+			locked, err := tx.Groups.LockGroup(ctx, *r.GroupID)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+			case err != nil:
+				return err
+			default:
+				g = locked
+				if role, err = tx.Groups.GetMemberRole(ctx, g.ID, resolverID); err != nil { return err }
+			}
+		}
+		if !resolver.IsAdmin && (r.TargetType == store.ReportGroup || !isModerator(role)) { return ErrReportNotFound }
+		if r.Status != store.ReportOpen { return errors.New("report is already resolved") }
+		switch action {
+		case store.ResolveDismiss:
+		case store.ResolveDeleteMessage:
+			if r.MessageID == nil { return errors.New("report has no message to delete") }
+			if err := tx.Messages.Delete(ctx, *r.MessageID); err != nil { return err }
+			if g != nil {
+				err := tx.Groups.RecordAudit(ctx, store.AuditRecord{GroupID: g.ID, ActorID: resolverID, Action: store.AuditMessageDeleted, TargetUserID: derefID(r.UserID), TargetID: *r.MessageID, Reason: note})
+				if err != nil { return err }
+			}
+		case store.ResolveBanish:
+			if g == nil || r.UserID == nil || r.TargetType == store.ReportGroup { return errors.New("report has no group member to banish") }
+			if !resolver.IsAdmin && g.OwnerID != resolverID { return errors.New("only owner can banish") }
+			if admitted, err = banishTx(ctx, tx, g, resolverID, *r.UserID, note); err != nil { return err }
+		case store.ResolveSuspend:
+			if !resolver.IsAdmin { return errors.New("only platform admins can suspend accounts") }
+			if r.UserID == nil { return errors.New("report has no account to suspend") }
+			if err := suspendTx(ctx, tx, resolverID, *r.UserID, note, map[string]int64{"report_id": r.ID}); err != nil { return err }
+		default:
+			return errors.New("action must be dismiss, delete_message, banish or suspend")
+		}
+		if err := tx.Reports.Resolve(ctx, r.ID, resolverID, action, note); err != nil { return err }
+		if g == nil { return nil }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{
+			GroupID: g.ID, ActorID: resolverID, Action: store.AuditReportResolved, TargetUserID: derefID(r.UserID), TargetID: r.ID, Reason: note,
+			After: map[string]string{"resolution": action},
+		})
+	})
+	if err != nil { return err }
+	if g != nil { s.groupSvc.notifyAdmitted(ctx, g, admitted) }
+	s.notifyReporter(ctx, r, action)
+	return nil
+}
+
+func (s *ReportService) notifyReporter(ctx context.Context, r *store.Report, action string) {
+	body := "Thanks for your report. We reviewed it and took action."
+	if action == store.ResolveDismiss { body = "Thanks for your report. We reviewed it and found no violation." }
+	_ = s.notifier.SendToUsers(ctx, []int64{r.ReporterID}, notify.Payload{
+		Type:  "report_resolved",
+		Title: "Report reviewed",
+		Body:  body,
+		Data:  map[string]string{"report_id": strconv.FormatInt(r.ID, 10), "resolution": action},
+	})
+}
+
+func derefID(p *int64) int64 {
+	if p == nil { return 0 }
+	return *p
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"secure-messaging-backend/internal/store"
+)
+
+const maxRulesLength = 20000
+
+// RulesAcceptanceError is returned by Send while the sender has not accepted
+// the group's current rules.
+type RulesAcceptanceError struct{ Version int }
+
+func (e *RulesAcceptanceError) Error() string { return "rules acceptance required" }
+
+// Rules returns a version of the group's rules, the latest when version is
+// 0, and the latest version the caller has accepted. Rules is nil when the
+// group has none.
+func (s *GroupService) Rules(ctx context.Context, groupID, userID int64, version int) (*store.GroupRules, int, error) {
+	if _, err := s.groups.GetGroup(ctx, groupID); err != nil { return nil, 0, err }
+	r, err := s.groups.GetRules(ctx, groupID, version)
+	if err != nil { return nil, 0, err }
+	if r == nil && version != 0 { return nil, 0, errors.New("rules version not found") }
+	accepted, err := s.groups.AcceptedRulesVersion(ctx, groupID, userID)
+	if err != nil { return nil, 0, err }
+	return r, accepted, nil
+}
+
+// SetRules publishes a new version of the group's rules; owner only. Members
+// must accept it before posting again. An empty body lifts the requirement.
+// The owner's acceptance is recorded with it.
+func (s *GroupService) SetRules(ctx context.Context, groupID, ownerID int64, body string) (*store.GroupRules, error) {
+	body = strings.TrimSpace(body)
+	if len(body) > maxRulesLength { return nil, fmt.Errorf("rules must be at most %d characters", maxRulesLength) }
+	var out *store.GroupRules
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		g, err := tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		if g.OwnerID != ownerID { return errors.New("only owner can manage rules") }
+		prev, err := tx.Groups.GetRules(ctx, groupID, 0)
+		if err != nil { return err }
+		if prev == nil && body == "" { return errors.New("group has no rules to clear") }
+		if prev != nil && prev.Body == body {
+			out = prev
+			return nil
+		}
+		if out, err = tx.Groups.PublishRules(ctx, groupID, ownerID, body); err != nil { return err }
+		if body != "" {
+			if err := tx.Groups.AcceptRules(ctx, groupID, ownerID, out.Version); err != nil { return err }
+		}
+		var before any
+		if prev != nil { before = map[string]int{"version": prev.Version} }
+		return tx.Groups.RecordAudit(ctx, store.AuditRecord{
+			GroupID: groupID, ActorID: ownerID, Action: store.AuditRulesUpdated, Before: before,
+			After: map[string]any{"version": out.Version, "cleared": body == ""},
+		})
+	})
+	return out, err
+}
+
+// AcceptRules records the member's acceptance of the group's rules. version
+// must be the current one, so members accept the text they were shown.
+func (s *GroupService) AcceptRules(ctx context.Context, groupID, userID int64, version int) error {
+	isMember, err := s.groups.IsMember(ctx, groupID, userID)
+	if err != nil { return err }
+	if !isMember { return errors.New("not a group member") }
+	r, err := s.groups.GetRules(ctx, groupID, 0)
+	if err != nil { return err }
+	if r == nil || r.Body == "" { return errors.New("group has no rules to accept") }
+	if version != r.Version { return fmt.Errorf("rules have changed; review version %d", r.Version) }
+	return s.groups.AcceptRules(ctx, groupID, userID, r.Version)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"secure-messaging-backend/internal/notify"
+	"secure-messaging-backend/internal/store"
+)
+
+// Succession rules, tried in the order the group lists them.
+const (
+	SuccessionDesignated = "designated"
+	SuccessionAdmin      = "admin"
+	SuccessionMember     = "member"
+)
+
+func validSuccessionRules(rules []string) error {
+	seen := map[string]bool{}
+	for _, r := range rules {
+		if r != SuccessionDesignated && r != SuccessionAdmin && r != SuccessionMember { return fmt.Errorf("invalid succession rule %q", r) }
+		if seen[r] { return fmt.Errorf("duplicate succession rule %q", r) }
+		seen[r] = true
+	}
+	return nil
+}
+
+// pickSuccessor applies the group's succession rules and returns the first
+// eligible member, or 0 if none qualifies.
+func pickSuccessor(ctx context.Context, tx *store.Tx, g *store.Group) (int64, error) {
+	for _, rule := range g.SuccessionRules {
+		switch rule {
+		case SuccessionDesignated:
+			if g.SuccessorID == nil || *g.SuccessorID == g.OwnerID { continue }
+			ok, err := tx.Groups.IsActiveMember(ctx, g.ID, *g.SuccessorID)
+			if err != nil { return 0, err }
+			if ok { return *g.SuccessorID, nil }
+		case SuccessionAdmin, SuccessionMember:
+			id, err := tx.Groups.LongestTenuredMember(ctx, g.ID, rule, g.OwnerID)
+			if err != nil { return 0, err }
+			if id != 0 { return id, nil }
+		}
+	}
+	return 0, nil
+}
+
+// succeedOwner hands the group to the successor chosen by its rules and
+// returns them, or 0 if nobody qualifies. Any pending transfer offer is
+// withdrawn. The previous owner is left as an admin; the caller records what
+// happens to them. The caller holds the group lock.
+func succeedOwner(ctx context.Context, tx *store.Tx, g *store.Group) (int64, error) {
+	next, err := pickSuccessor(ctx, tx, g)
+	if err != nil || next == 0 { return 0, err }
+	t, err := tx.Groups.CancelPendingOwnershipTransfer(ctx, g.ID)
+	if err != nil { return 0, err }
+	if t != nil {
+		if err := tx.Groups.RecordOwnershipTransferEvent(ctx, t, 0, store.TransferCancelled); err != nil { return 0, err }
+		if err := recordTransferAudit(ctx, tx, t, 0, store.AuditOwnershipCancelled); err != nil { return 0, err }
+	}
+	if err := tx.Groups.TransferOwner(ctx, g.ID, next); err != nil { return 0, err }
+	if g.SuccessorID != nil && *g.SuccessorID == next {
+		gs := g.GroupSettings
+		gs.SuccessorID = nil
+		if err := tx.Groups.UpdateSettings(ctx, g.ID, gs); err != nil { return 0, err }
+	}
+	role := store.RoleOwner
+	if err := tx.Groups.RecordMembershipEvent(ctx, g.ID, next, 0, store.EventRoleChanged, &role); err != nil { return 0, err }
+	err = tx.Groups.RecordAudit(ctx, store.AuditRecord{
+		GroupID: g.ID, Action: store.AuditOwnershipSucceeded, TargetUserID: next,
+		Before: map[string]int64{"owner_id": g.OwnerID}, After: map[string]int64{"owner_id": next},
+	})
+	return next, err
+}
+
+func (s *GroupService) notifySuccessor(ctx context.Context, g *store.Group, userID int64) {
+	if userID == 0 { return }
+	_ = s.notifier.SendToUsers(ctx, []int64{userID}, notify.Payload{
+		Type:  "ownership_succeeded",
+		Title: "You are now the owner",
+		Body:  "Ownership of " + g.Name + " has passed to you.",
+		Data:  map[string]string{"group_id": strconv.FormatInt(g.ID, 10)},
+	})
+}
+
+// SucceedAbsentOwners is run periodically to replace owners who have
+// deactivated their account or been inactive longer than their group
+// allows. Groups with no eligible successor are left as they are.
+func (s *GroupService) SucceedAbsentOwners(ctx context.Context) error {
+	var errs []error
+	var after int64
+	for {
+		ids, err := s.groups.ListGroupsNeedingSuccession(ctx, after, 100)
+		if err != nil { return err }
+		if len(ids) == 0 { break }
+		after = ids[len(ids)-1]
+		for _, id := range ids {
+			if err := s.succeedAbsentOwner(ctx, id); err != nil { errs = append(errs, fmt.Errorf("group %d: %w", id, err)) }
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *GroupService) succeedAbsentOwner(ctx context.Context, groupID int64) error {
+	var g *store.Group
+	var next int64
+	err := s.uow.Do(ctx, func(tx *store.Tx) error {
+		var err error
+		g, err = tx.Groups.LockGroup(ctx, groupID)
+		if err != nil { return err }
+		needs, err := tx.Groups.OwnerNeedsSuccession(ctx, groupID)
+		if err != nil || !needs { return err }
+		next, err = succeedOwner(ctx, tx, g)
+		if err != nil || next == 0 { return err }
+		role := store.RoleAdmin
+		return tx.Groups.RecordMembershipEvent(ctx, groupID, g.OwnerID, 0, store.EventRoleChanged, &role)
+	})
+	if err != nil { return err }
+	s.notifySuccessor(ctx, g, next)
+	return nil
+}
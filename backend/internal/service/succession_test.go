@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"secure-messaging-backend/internal/store"
+)
+
+func TestSuccessionSkipsSuspendedAccounts(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	users := e.createUsers(t, 4)
+	owner, designated, senior, junior := users[0], users[1], users[2], users[3]
+	g := e.createGroup(t, owner, "open", 10, false)
+	for _, u := range users[1:] {
+		if _, err := e.groups.Join(ctx, JoinInput{GroupID: g.ID, UserID: u}); err != nil { t.Fatal(err) }
+	}
+	for _, u := range []int64{senior, junior} {
+		if err := e.groups.SetMemberRole(ctx, g.ID, owner, u, store.RoleAdmin); err != nil { t.Fatal(err) }
+	}
+	if _, err := e.groups.UpdateSettings(ctx, g.ID, owner, SettingsPatch{SuccessorID: &designated}); err != nil { t.Fatal(err) }
+	// The designated successor and the longest-serving admin are suspended.
+	for _, u := range []int64{designated, senior} {
+		if err := e.users.Suspend(ctx, u, nil); err != nil { t.Fatal(err) }
+	}
+
+	if err := e.groups.Leave(ctx, g.ID, owner); err != nil { t.Fatal(err) }
+	got, err := e.store.GetGroup(ctx, g.ID)
+	if err != nil { t.Fatal(err) }
+	if got.OwnerID != junior { t.Errorf("owner = %d, want active admin %d", got.OwnerID, junior) }
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxSlowModeSeconds caps the slow-mode interval at six hours.
+const maxSlowModeSeconds = 6 * 60 * 60
+
+// RateLimitedError is returned by Send when the sender has hit the group's
+// slow mode or the per-member burst limit.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("sending too fast; retry in %ds", e.Seconds())
+}
+
+// Seconds is RetryAfter rounded up to whole seconds, as sent in Retry-After.
+func (e *RateLimitedError) Seconds() int {
+	return int((e.RetryAfter + time.Second - 1) / time.Second)
+}
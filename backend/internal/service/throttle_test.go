@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"secure-messaging-backend/internal/store"
+)
+
+func TestSendThrottle(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	users := e.createUsers(t, 3)
+	owner, member, burster := users[0], users[1], users[2]
+	g := e.createGroup(t, owner, "open", 10, false)
+	for _, u := range []int64{member, burster} {
+		if _, err := e.groups.Join(ctx, JoinInput{GroupID: g.ID, UserID: u}); err != nil { t.Fatal(err) }
+	}
+	slow := 60
+	if _, err := e.groups.UpdateSettings(ctx, g.ID, owner, SettingsPatch{SlowModeSeconds: &slow}); err != nil { t.Fatal(err) }
+	if _, err := e.groups.CreateAutomodRule(ctx, g.ID, owner, AutomodRuleInput{Kind: store.AutomodKeyword, Pattern: "forbidden", Action: store.AutomodReject}); err != nil { t.Fatal(err) }
+	send := func(user int64, text string) error {
+		_, err := e.messages.Send(ctx, SendMessageInput{GroupID: g.ID, SenderID: user, Plain: []byte(text)})
+		return err
+	}
+
+	// A send automod rejects is never stored and doesn't use up the slot.
+	if err := send(member, "forbidden"); !errors.Is(err, ErrAutomodRejected) { t.Fatalf("rejected send: err = %v", err) }
+	if err := send(member, "hello"); err != nil { t.Fatalf("first stored send: %v", err) }
+	var limited *RateLimitedError
+	if err := send(member, "again"); !errors.As(err, &limited) { t.Fatalf("second send: err = %v, want rate limited", err) }
+	if limited.RetryAfter <= 0 || limited.RetryAfter > 60*time.Second { t.Errorf("retry after = %v", limited.RetryAfter) }
+	// Moderators are not throttled.
+	for i := 0; i < 3; i++ {
+		if err := send(owner, "announcement"); err != nil { t.Fatalf("owner send %d: %v", i, err) }
+	}
+
+	// Without slow mode the burst limit applies.
+	off := 0
+	if _, err := e.groups.UpdateSettings(ctx, g.ID, owner, SettingsPatch{SlowModeSeconds: &off}); err != nil { t.Fatal(err) }
+	e.cfg.MessageBurstLimit = 2
+	for i := 0; i < 2; i++ {
+		if err := send(burster, "burst"); err != nil { t.Fatalf("burst send %d: %v", i, err) }
+	}
+	if err := send(burster, "burst"); !errors.As(err, &limited) { t.Fatalf("send past burst: err = %v, want rate limited", err) }
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"secure-messaging-backend/internal/notify"
+	"secure-messaging-backend/internal/store"
+)
+
+// admitFromWaitlist fills free seats in g from its waitlist in FIFO order and
+// returns the admitted user ids. Entries for users banned since queuing are
+// dropped. The caller holds the group lock.
+func admitFromWaitlist(ctx context.Context, tx *store.Tx, g *store.Group) ([]int64, error) {
+	if g.Type != "open" || !g.WaitlistEnabled || g.Archived() { return nil, nil }
+	count, err := tx.Groups.CountMembers(ctx, g.ID)
+	if err != nil { return nil, err }
+	var admitted []int64
+	for count < g.MaxMembers {
+		e, err := tx.Groups.PopWaitlist(ctx, g.ID)
+		if err != nil { return nil, err }
+		if e == nil { break }
+		banned, err := tx.Groups.IsBanned(ctx, g.ID, e.UserID)
+		if err != nil { return nil, err }
+		if banned { continue }
+		if err := tx.Groups.AddMember(ctx, g.ID, e.UserID, store.RoleMember); err != nil { return nil, err }
+		if err := tx.Groups.RecordMembershipEvent(ctx, g.ID, e.UserID, 0, store.EventJoined, nil); err != nil { return nil, err }
+		admitted = append(admitted, e.UserID)
+		count++
+	}
+	return admitted, nil
+}
+
+// notifyAdmitted tells users admitted from the waitlist. It runs after
+// commit, so a delivery failure doesn't undo the admission.
+func (s *GroupService) notifyAdmitted(ctx context.Context, g *store.Group, userIDs []int64) {
+	if len(userIDs) == 0 { return }
+	_ = s.notifier.SendToUsers(ctx, userIDs, notify.Payload{
+		Type:  "waitlist_admitted",
+		Title: "You're in",
+		Body:  "A seat opened up in " + g.Name + " and you have been added.",
+		Data:  map[string]string{"group_id": strconv.FormatInt(g.ID, 10)},
+	})
+}
+
+type WaitlistStatus struct {
+	Position int
+	Length   int
+}
+
+// WaitlistPosition reports the caller's place in the group's waitlist.
+func (s *GroupService) WaitlistPosition(ctx context.Context, groupID, userID int64) (*WaitlistStatus, error) {
+	if _, err := s.groups.GetGroup(ctx, groupID); err != nil { return nil, err }
+	pos, err := s.groups.WaitlistPosition(ctx, groupID, userID)
+	if err != nil { return nil, err }
+	if pos == 0 { return nil, errors.New("not on the waitlist") }
+	n, err := s.groups.WaitlistLength(ctx, groupID)
+	if err != nil { return nil, err }
+	return &WaitlistStatus{Position: pos, Length: n}, nil
+}
+
+// LeaveWaitlist drops the caller's place in the queue.
+func (s *GroupService) LeaveWaitlist(ctx context.Context, groupID, userID int64) error {
+	return s.groups.RemoveFromWaitlist(ctx, groupID, userID)
+}
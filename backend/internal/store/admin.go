@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Admin audit log actions.
+const (
+	AdminUserSuspended     = "user_suspended"
+	AdminUserUnsuspended   = "user_unsuspended"
+	AdminGroupDeleted      = "group_deleted"
+	AdminOwnershipTransfer = "ownership_transferred"
+)
+
+// AdminAuditRecord is a new admin audit log entry; zero targets are stored
+// as NULL and Details as JSON.
+type AdminAuditRecord struct {
+	ActorID       int64
+	Action        string
+	TargetUserID  int64
+	TargetGroupID int64
+	Reason        *string
+	Details       any
+}
+
+// AdminAuditEntry is a stored admin audit log row; Details is raw JSON.
+type AdminAuditEntry struct {
+	ID            int64     `db:"id"`
+	ActorID       int64     `db:"actor_id"`
+	Action        string    `db:"action"`
+	TargetUserID  *int64    `db:"target_user_id"`
+	TargetGroupID *int64    `db:"target_group_id"`
+	Reason        *string   `db:"reason"`
+	Details       *string   `db:"details"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// AdminAuditFilter narrows an admin audit log listing; zero fields match
+// everything.
+type AdminAuditFilter struct {
+	Action        string
+	ActorID       int64
+	TargetUserID  int64
+	TargetGroupID int64
+}
+
+// SystemStats is a point-in-time summary of the platform.
+type SystemStats struct {
+	Users          int   `db:"users" json:"users"`
+	ActiveUsers    int   `db:"active_users" json:"active_users"`
+	SuspendedUsers int   `db:"suspended_users" json:"suspended_users"`
+	Groups         int   `db:"groups" json:"groups"`
+	DeletedGroups  int   `db:"deleted_groups" json:"deleted_groups"`
+	Messages       int64 `db:"messages" json:"messages"`
+	MessagesToday  int64 `db:"messages_today" json:"messages_today"`
+	OpenReports    int   `db:"open_reports" json:"open_reports"`
+}
+
+type AdminStore struct{ db DBTX }
+
+func NewAdminStore(db *sqlx.DB) *AdminStore { return &AdminStore{db: db} }
+
+func (s *AdminStore) RecordAudit(ctx context.Context, r AdminAuditRecord) error {
+	details, err := auditJSON(r.Details)
+	if err != nil { return err }
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO admin_audit_log (actor_id, action, target_user_id, target_group_id, reason, details)
+		VALUES ($1, $2, NULLIF($3,0), NULLIF($4,0), $5, $6::jsonb)
+	`, r.ActorID, r.Action, r.TargetUserID, r.TargetGroupID, r.Reason, details)
+	return err
+}
+
+// ListAudit pages the admin audit log newest first. After is a cursor over id.
+func (s *AdminStore) ListAudit(ctx context.Context, f AdminAuditFilter, after *Cursor, limit int) ([]AdminAuditEntry, error) {
+	var afterID int64
+	if after != nil { afterID = after.ID }
+	rows := []AdminAuditEntry{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT id, actor_id, action, target_user_id, target_group_id, reason, details::text, created_at
+		FROM admin_audit_log
+		WHERE ($1='' OR action=$1) AND ($2=0 OR actor_id=$2) AND ($3=0 OR target_user_id=$3) AND ($4=0 OR target_group_id=$4)
+			AND ($5=0 OR id < $5)
+		ORDER BY id DESC LIMIT $6
+	`, f.Action, f.ActorID, f.TargetUserID, f.TargetGroupID, afterID, limit)
+	return rows, err
+}
+
+// Stats counts users, groups, messages and open reports. Active users were
+// seen within activeSince.
+func (s *AdminStore) Stats(ctx context.Context, activeSince time.Time) (*SystemStats, error) {
+	st := &SystemStats{}
+	err := s.db.GetContext(ctx, st, `
+		SELECT
+			(SELECT COUNT(*) FROM users WHERE deactivated_at IS NULL) AS users,
+			(SELECT COUNT(*) FROM users WHERE deactivated_at IS NULL AND last_seen_at >= $1) AS active_users,
+			(SELECT COUNT(*) FROM users WHERE suspended_at IS NOT NULL) AS suspended_users,
+			(SELECT COUNT(*) FROM groups WHERE deleted_at IS NULL) AS groups,
+			(SELECT COUNT(*) FROM groups WHERE deleted_at IS NOT NULL) AS deleted_groups,
+			(SELECT COUNT(*) FROM messages) AS messages,
+			(SELECT COUNT(*) FROM messages WHERE created_at >= date_trunc('day', now())) AS messages_today,
+			(SELECT COUNT(*) FROM reports WHERE status='open') AS open_reports
+	`, activeSince)
+	return st, err
+}
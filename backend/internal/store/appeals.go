@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	AppealOpen    = "open"
+	AppealGranted = "granted"
+	AppealDenied  = "denied"
+)
+
+// ErrAppealOpen is returned when the user already has an open appeal
+// against the group's ban.
+var ErrAppealOpen = errors.New("you already have an open appeal for this group")
+
+// BanAppeal is a banned user's request to have the ban lifted.
+type BanAppeal struct {
+	ID             int64      `db:"id"`
+	GroupID        int64      `db:"group_id"`
+	UserID         int64      `db:"user_id"`
+	Message        string     `db:"message"`
+	Status         string     `db:"status"`
+	DecidedBy      *int64     `db:"decided_by"`
+	DecisionReason *string    `db:"decision_reason"`
+	DecidedAt      *time.Time `db:"decided_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+}
+
+const banAppealColumns = `id, group_id, user_id, message, status, decided_by, decision_reason, decided_at, created_at`
+
+func (s *GroupStore) CreateAppeal(ctx context.Context, groupID, userID int64, message string) (*BanAppeal, error) {
+	a := &BanAppeal{}
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO ban_appeals (group_id, user_id, message) VALUES ($1,$2,$3)
+		RETURNING `+banAppealColumns+`
+	`, groupID, userID, message).StructScan(a)
+	if isUniqueViolation(err) { return nil, ErrAppealOpen }
+	return a, err
+}
+
+// LastDeniedAppeal returns when the user's latest denied appeal against the
+// group was decided, or nil.
+func (s *GroupStore) LastDeniedAppeal(ctx context.Context, groupID, userID int64) (*time.Time, error) {
+	var t *time.Time
+	err := s.db.GetContext(ctx, &t, `
+		SELECT MAX(decided_at) FROM ban_appeals WHERE group_id=$1 AND user_id=$2 AND status='denied'
+	`, groupID, userID)
+	return t, err
+}
+
+// ListAppeals pages a group's appeals oldest first. After is a cursor over id.
+func (s *GroupStore) ListAppeals(ctx context.Context, groupID int64, status string, after *Cursor, limit int) ([]BanAppeal, error) {
+	var afterID int64
+	if after != nil { afterID = after.ID }
+	rows := []BanAppeal{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT `+banAppealColumns+` FROM ban_appeals
+		WHERE group_id=$1 AND ($2='' OR status=$2) AND id > $3
+		ORDER BY id LIMIT $4
+	`, groupID, status, afterID, limit)
+	return rows, err
+}
+
+// ListUserAppeals returns the user's appeals across groups, newest first.
+func (s *GroupStore) ListUserAppeals(ctx context.Context, userID int64) ([]BanAppeal, error) {
+	rows := []BanAppeal{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT `+banAppealColumns+` FROM ban_appeals WHERE user_id=$1 ORDER BY id DESC LIMIT 100
+	`, userID)
+	return rows, err
+}
+
+// DecideAppeal closes an open appeal with status. It returns nil if the
+// appeal is not open in this group, so concurrent reviews decide it only
+// once.
+func (s *GroupStore) DecideAppeal(ctx context.Context, groupID, appealID, decidedBy int64, status string, reason *string) (*BanAppeal, error) {
+	rows := []BanAppeal{}
+	err := s.db.SelectContext(ctx, &rows, `
+		UPDATE ban_appeals SET status=$4, decided_by=$3, decision_reason=$5, decided_at=now()
+		WHERE id=$2 AND group_id=$1 AND status='open'
+		RETURNING `+banAppealColumns+`
+	`, groupID, appealID, decidedBy, status, reason)
+	if err != nil || len(rows) == 0 { return nil, err }
+	return &rows[0], nil
+}
+
+// GrantOpenAppeal closes the user's open appeal, if any, as granted; used
+// when a ban is lifted directly.
+func (s *GroupStore) GrantOpenAppeal(ctx context.Context, groupID, userID, decidedBy int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE ban_appeals SET status='granted', decided_by=$3, decided_at=now()
+		WHERE group_id=$1 AND user_id=$2 AND status='open'
+	`, groupID, userID, decidedBy)
+	return err
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Audit log actions.
+const (
+	AuditSettingsUpdated      = "settings_updated"
+	AuditQuestionsUpdated     = "questions_updated"
+	AuditRulesUpdated         = "rules_updated"
+	AuditGroupDeleted         = "group_deleted"
+	AuditGroupRestored        = "group_restored"
+	AuditGroupArchived        = "group_archived"
+	AuditGroupUnarchived      = "group_unarchived"
+	AuditMemberBanished       = "member_banished"
+	AuditMemberUnbanned       = "member_unbanned"
+	AuditAppealDenied         = "ban_appeal_denied"
+	AuditRoleChanged          = "role_changed"
+	AuditMemberMuted          = "member_muted"
+	AuditMemberUnmuted        = "member_unmuted"
+	AuditJoinApproved         = "join_request_approved"
+	AuditJoinDeclined         = "join_request_declined"
+	AuditOwnershipOffered     = "ownership_offered"
+	AuditOwnershipAccepted    = "ownership_accepted"
+	AuditOwnershipDeclined    = "ownership_declined"
+	AuditOwnershipCancelled   = "ownership_cancelled"
+	AuditOwnershipSucceeded   = "ownership_succeeded"
+	AuditOwnershipForced      = "ownership_forced"
+	AuditInviteCreated        = "invite_created"
+	AuditInviteLinkCreated    = "invite_link_created"
+	AuditInviteRevoked        = "invite_revoked"
+	AuditChannelCreated       = "channel_created"
+	AuditChannelRenamed       = "channel_renamed"
+	AuditChannelArchived      = "channel_archived"
+	AuditChannelUnarchived    = "channel_unarchived"
+	AuditChannelsReordered    = "channels_reordered"
+	AuditChannelMemberAdded   = "channel_member_added"
+	AuditChannelMemberRemoved = "channel_member_removed"
+	AuditMessageApproved      = "message_approved"
+	AuditMessageRejected      = "message_rejected"
+	AuditAutomodRuleCreated   = "automod_rule_created"
+	AuditAutomodRuleUpdated   = "automod_rule_updated"
+	AuditAutomodRuleDeleted   = "automod_rule_deleted"
+	AuditMessageDeleted       = "message_deleted"
+	AuditReportResolved       = "report_resolved"
+)
+
+// AuditRecord is a new audit log entry. Zero ids are stored as NULL; a zero
+// ActorID means a system job. Before and After are stored as JSON.
+type AuditRecord struct {
+	GroupID      int64
+	ActorID      int64
+	Action       string
+	TargetUserID int64
+	TargetID     int64
+	Reason       *string
+	Before       any
+	After        any
+}
+
+// AuditEntry is a stored audit log row; Before and After are raw JSON.
+type AuditEntry struct {
+	ID           int64     `db:"id"`
+	GroupID      int64     `db:"group_id"`
+	ActorID      *int64    `db:"actor_id"`
+	Action       string    `db:"action"`
+	TargetUserID *int64    `db:"target_user_id"`
+	TargetID     *int64    `db:"target_id"`
+	Reason       *string   `db:"reason"`
+	Before       *string   `db:"before"`
+	After        *string   `db:"after"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// AuditFilter narrows an audit log listing; zero fields match everything.
+type AuditFilter struct {
+	Action       string
+	ActorID      int64
+	TargetUserID int64
+	Since        *time.Time
+	Until        *time.Time
+}
+
+func auditJSON(v any) (*string, error) {
+	if v == nil { return nil, nil }
+	b, err := json.Marshal(v)
+	if err != nil { return nil, err }
+	s := string(b)
+	return &s, nil
+}
+
+func (s *GroupStore) RecordAudit(ctx context.Context, r AuditRecord) error {
+	before, err := auditJSON(r.Before)
+	if err != nil { return err }
+	after, err := auditJSON(r.After)
+	if err != nil { return err }
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO audit_log (group_id, actor_id, action, target_user_id, target_id, reason, before, after)
+		VALUES ($1, NULLIF($2,0), $3, NULLIF($4,0), NULLIF($5,0), $6, $7::jsonb, $8::jsonb)
+	`, r.GroupID, r.ActorID, r.Action, r.TargetUserID, r.TargetID, r.Reason, before, after)
+	return err
+}
+
+// ListAudit pages a group's audit log newest first. After is a cursor over id.
+func (s *GroupStore) ListAudit(ctx context.Context, groupID int64, f AuditFilter, after *Cursor, limit int) ([]AuditEntry, error) {
+	var afterID int64
+	if after != nil { afterID = after.ID }
+	rows := []AuditEntry{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT id, group_id, actor_id, action, target_user_id, target_id, reason, before::text, after::text, created_at
+		FROM audit_log
+		WHERE group_id=$1 AND ($2='' OR action=$2) AND ($3=0 OR actor_id=$3) AND ($4=0 OR target_user_id=$4)
+			AND ($5::timestamptz IS NULL OR created_at >= $5) AND ($6::timestamptz IS NULL OR created_at < $6)
+			AND ($7=0 OR id < $7)
+		ORDER BY id DESC LIMIT $8
+	`, groupID, f.Action, f.ActorID, f.TargetUserID, f.Since, f.Until, afterID, limit)
+	return rows, err
+}
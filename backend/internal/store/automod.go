@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Automod rule kinds.
+const (
+	AutomodKeyword    = "keyword"
+	AutomodRegex      = "regex"
+	AutomodLinkDomain = "link_domain"
+	AutomodMentions   = "mentions"
+)
+
+// Automod actions, strongest first.
+const (
+	AutomodReject = "reject"
+	AutomodHold   = "hold"
+	AutomodFlag   = "flag"
+)
+
+type AutomodRule struct {
+	ID        int64      `db:"id"`
+	GroupID   int64      `db:"group_id"`
+	Kind      string     `db:"kind"`
+	Pattern   string     `db:"pattern"`
+	Threshold int        `db:"threshold"`
+	Action    string     `db:"action"`
+	Enabled   bool       `db:"enabled"`
+	HitCount  int64      `db:"hit_count"`
+	LastHitAt *time.Time `db:"last_hit_at"`
+	CreatedBy *int64     `db:"created_by"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+const automodRuleColumns = `id, group_id, kind, pattern, threshold, action, enabled, hit_count, last_hit_at, created_by, created_at`
+
+// FlaggedMessage is a message automod flagged to moderators.
+type FlaggedMessage struct {
+	Message
+	RuleIDs   pq.Int64Array `db:"rule_ids"`
+	FlaggedAt time.Time     `db:"flagged_at"`
+}
+
+func (s *GroupStore) CreateAutomodRule(ctx context.Context, r AutomodRule, createdBy int64) (*AutomodRule, error) {
+	out := &AutomodRule{}
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO automod_rules (group_id, kind, pattern, threshold, action, created_by)
+		VALUES ($1,$2,$3,$4,$5,NULLIF($6,0))
+		RETURNING `+automodRuleColumns+`
+	`, r.GroupID, r.Kind, r.Pattern, r.Threshold, r.Action, createdBy).StructScan(out)
+	return out, err
+}
+
+// GetAutomodRule returns the group's rule, or nil if there is none.
+func (s *GroupStore) GetAutomodRule(ctx context.Context, groupID, ruleID int64) (*AutomodRule, error) {
+	r := &AutomodRule{}
+	err := s.db.GetContext(ctx, r, `SELECT `+automodRuleColumns+` FROM automod_rules WHERE id=$1 AND group_id=$2`, ruleID, groupID)
+	if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+	return r, err
+}
+
+// ListAutomodRules returns the group's rules in creation order; enabledOnly
+// skips disabled ones.
+func (s *GroupStore) ListAutomodRules(ctx context.Context, groupID int64, enabledOnly bool) ([]AutomodRule, error) {
+	rows := []AutomodRule{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT `+automodRuleColumns+` FROM automod_rules
+		WHERE group_id=$1 AND (NOT $2 OR enabled)
+		ORDER BY id
+	`, groupID, enabledOnly)
+	return rows, err
+}
+
+func (s *GroupStore) CountAutomodRules(ctx context.Context, groupID int64) (int, error) {
+	var n int
+	err := s.db.GetContext(ctx, &n, `SELECT COUNT(*) FROM automod_rules WHERE group_id=$1`, groupID)
+	return n, err
+}
+
+func (s *GroupStore) UpdateAutomodRule(ctx context.Context, ruleID int64, action string, enabled bool) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE automod_rules SET action=$2, enabled=$3 WHERE id=$1`, ruleID, action, enabled)
+	return err
+}
+
+func (s *GroupStore) DeleteAutomodRule(ctx context.Context, ruleID int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM automod_rules WHERE id=$1`, ruleID)
+	return err
+}
+
+// RecordAutomodHits bumps the fire counters of the given rules.
+func (s *GroupStore) RecordAutomodHits(ctx context.Context, ruleIDs []int64) error {
+	if len(ruleIDs) == 0 { return nil }
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE automod_rules SET hit_count = hit_count + 1, last_hit_at = now() WHERE id = ANY($1)
+	`, pq.Array(ruleIDs))
+	return err
+}
+
+func (s *GroupStore) FlagMessage(ctx context.Context, groupID, messageID int64, ruleIDs []int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO automod_flags (message_id, group_id, rule_ids) VALUES ($1,$2,$3)
+		ON CONFLICT (message_id) DO NOTHING
+	`, messageID, groupID, pq.Array(ruleIDs))
+	return err
+}
+
+// ListFlaggedMessages pages the group's flagged messages newest first.
+// After is a cursor over message id.
+func (s *GroupStore) ListFlaggedMessages(ctx context.Context, groupID int64, after *Cursor, limit int) ([]FlaggedMessage, error) {
+	var afterID int64
+	if after != nil { afterID = after.ID }
+	rows := []FlaggedMessage{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT m.id, m.group_id, m.channel_id, m.sender_id, m.ciphertext, m.iv, m.status, m.created_at,
+			f.rule_ids, f.created_at AS flagged_at
+		FROM automod_flags f JOIN messages m ON m.id=f.message_id
+		WHERE f.group_id=$1 AND ($2=0 OR f.message_id < $2)
+		ORDER BY f.message_id DESC LIMIT $3
+	`, groupID, afterID, limit)
+	return rows, err
+}
+
+// ModeratorIDs returns the group's owner and admins.
+func (s *GroupStore) ModeratorIDs(ctx context.Context, groupID int64) ([]int64, error) {
+	ids := []int64{}
+	err := s.db.SelectContext(ctx, &ids, `
+		SELECT user_id FROM group_members WHERE group_id=$1 AND role IN ('owner','admin') ORDER BY user_id
+	`, groupID)
+	return ids, err
+}
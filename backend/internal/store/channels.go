@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DefaultChannelName is the channel every group is created with.
+const DefaultChannelName = "general"
+
+// Channel is a message stream within a group. Restricted channels are
+// visible only to their members and to the group's owner and admins.
+type Channel struct {
+	ID         int64      `db:"id"`
+	GroupID    int64      `db:"group_id"`
+	Name       string     `db:"name"`
+	Position   int        `db:"position"`
+	Restricted bool       `db:"restricted"`
+	ArchivedAt *time.Time `db:"archived_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+const channelColumns = `id, group_id, name, position, restricted, archived_at, created_at`
+
+func (c *Channel) Archived() bool { return c.ArchivedAt != nil }
+
+// channelVisible is the condition for channel c being visible to user $uid
+// holding group role $role; it takes the placeholders as arguments.
+func channelVisible(c, uid, role string) string {
+	return `(NOT ` + c + `.restricted OR ` + role + ` IN ('owner','admin') OR EXISTS(SELECT 1 FROM channel_members cm WHERE cm.channel_id=` + c + `.id AND cm.user_id=` + uid + `))`
+}
+
+var ErrChannelExists = errors.New("a channel with that name already exists")
+
+// CreateChannel appends a channel after the group's existing ones.
+func (s *GroupStore) CreateChannel(ctx context.Context, groupID int64, name string, restricted bool) (*Channel, error) {
+	ch := &Channel{}
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO channels (group_id, name, restricted, position)
+		VALUES ($1,$2,$3,(SELECT COALESCE(MAX(position)+1, 0) FROM channels WHERE group_id=$1))
+		RETURNING `+channelColumns+`
+	`, groupID, name, restricted).StructScan(ch)
+	if isUniqueViolation(err) { return nil, ErrChannelExists }
+	return ch, err
+}
+
+// GetChannel returns the group's channel, or nil if it has no such channel.
+func (s *GroupStore) GetChannel(ctx context.Context, groupID, channelID int64) (*Channel, error) {
+	ch := &Channel{}
+	err := s.db.GetContext(ctx, ch, `SELECT `+channelColumns+` FROM channels WHERE id=$1 AND group_id=$2`, channelID, groupID)
+	if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+	return ch, err
+}
+
+// DefaultChannel returns the group's first unarchived channel, or nil.
+func (s *GroupStore) DefaultChannel(ctx context.Context, groupID int64) (*Channel, error) {
+	ch := &Channel{}
+	err := s.db.GetContext(ctx, ch, `
+		SELECT `+channelColumns+` FROM channels WHERE group_id=$1 AND archived_at IS NULL
+		ORDER BY position, id LIMIT 1
+	`, groupID)
+	if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+	return ch, err
+}
+
+// ListChannels returns the group's channels visible to a member with the
+// given role, in display order.
+func (s *GroupStore) ListChannels(ctx context.Context, groupID, userID int64, role string, includeArchived bool) ([]Channel, error) {
+	rows := []Channel{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT `+channelColumns+` FROM channels c
+		WHERE c.group_id=$1 AND ($4 OR c.archived_at IS NULL) AND `+channelVisible("c", "$2", "$3")+`
+		ORDER BY c.position, c.id
+	`, groupID, userID, role, includeArchived)
+	return rows, err
+}
+
+func (s *GroupStore) RenameChannel(ctx context.Context, channelID int64, name string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE channels SET name=$2 WHERE id=$1`, channelID, name)
+	if isUniqueViolation(err) { return ErrChannelExists }
+	return err
+}
+
+func (s *GroupStore) SetChannelArchived(ctx context.Context, channelID int64, archived bool) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE channels SET archived_at = CASE WHEN $2 THEN now() END WHERE id=$1`, channelID, archived)
+	return err
+}
+
+// CountActiveChannels counts the group's unarchived channels.
+func (s *GroupStore) CountActiveChannels(ctx context.Context, groupID int64) (int, error) {
+	var n int
+	err := s.db.GetContext(ctx, &n, `SELECT COUNT(*) FROM channels WHERE group_id=$1 AND archived_at IS NULL`, groupID)
+	return n, err
+}
+
+// ReorderChannels sets positions to follow ids; channels not listed keep
+// theirs after the listed ones.
+func (s *GroupStore) ReorderChannels(ctx context.Context, groupID int64, ids []int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE channels c SET position = COALESCE(
+			(SELECT o.ord - 1 FROM unnest($2::bigint[]) WITH ORDINALITY AS o(id, ord) WHERE o.id=c.id),
+			$3 + c.position)
+		WHERE c.group_id=$1
+	`, groupID, pq.Int64Array(ids), len(ids))
+	return err
+}
+
+func (s *GroupStore) AddChannelMember(ctx context.Context, channelID, userID int64) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO channel_members (channel_id, user_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`, channelID, userID)
+	return err
+}
+
+func (s *GroupStore) RemoveChannelMember(ctx context.Context, channelID, userID int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM channel_members WHERE channel_id=$1 AND user_id=$2`, channelID, userID)
+	return err
+}
+
+// CanSeeChannel reports whether a member with the given group role can read
+// and post in ch.
+func (s *GroupStore) CanSeeChannel(ctx context.Context, ch *Channel, userID int64, role string) (bool, error) {
+	if !ch.Restricted || role == RoleOwner || role == RoleAdmin { return true, nil }
+	var ok bool
+	err := s.db.GetContext(ctx, &ok, `SELECT EXISTS(SELECT 1 FROM channel_members WHERE channel_id=$1 AND user_id=$2)`, ch.ID, userID)
+	return ok, err
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,36 @@
+package store
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Cursor is a keyset position: a sort key plus the row id as tie-breaker.
+// Time-ordered listings store the timestamp as Unix microseconds in Key.
+type Cursor struct {
+	Key int64
+	ID  int64
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+func TimeCursor(t time.Time, id int64) Cursor { return Cursor{Key: t.UnixMicro(), ID: id} }
+
+func (c Cursor) Time() time.Time { return time.UnixMicro(c.Key) }
+
+// Encode returns the opaque token handed to clients.
+func (c Cursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.Key, c.ID)))
+}
+
+// DecodeCursor parses a token produced by Encode. An empty token yields nil.
+func DecodeCursor(tok string) (*Cursor, error) {
+	if tok == "" { return nil, nil }
+	b, err := base64.RawURLEncoding.DecodeString(tok)
+	if err != nil { return nil, ErrInvalidCursor }
+	c := &Cursor{}
+	if _, err := fmt.Sscanf(string(b), "%d:%d", &c.Key, &c.ID); err != nil { return nil, ErrInvalidCursor }
+	return c, nil
+}
@@ -4,36 +4,118 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Group struct {
-	ID               int64      `db:"id"`
-	Name             string     `db:"name"`
-	OwnerID          int64      `db:"owner_id"`
-	Type             string     `db:"type"`
-	MaxMembers       int        `db:"max_members"`
-	EncryptedKey     string     `db:"encrypted_group_key"`
-	KeyNonce         string     `db:"key_nonce"`
-	CreatedAt        time.Time  `db:"created_at"`
-	DeletedAt        *time.Time `db:"deleted_at"`
+	ID               int64          `db:"id"`
+	Name             string         `db:"name"`
+	OwnerID          int64          `db:"owner_id"`
+	Type             string         `db:"type"`
+	MaxMembers       int            `db:"max_members"`
+	EncryptedKey     string         `db:"encrypted_group_key"`
+	KeyNonce         string         `db:"key_nonce"`
+	GroupSettings
+	Description      string         `db:"description"`
+	Tags             pq.StringArray `db:"tags"`
+	MemberCount      int            `db:"member_count"`
+	LastActivityAt   time.Time      `db:"last_activity_at"`
+	CreatedAt        time.Time      `db:"created_at"`
+	DeletedAt        *time.Time     `db:"deleted_at"`
+	ArchivedAt       *time.Time     `db:"archived_at"`
+	ArchivedBy       *int64         `db:"archived_by"`
+}
+
+func (g *Group) Archived() bool { return g.ArchivedAt != nil }
+
+// GroupSettings are the owner-configurable policies of a group.
+type GroupSettings struct {
+	MembersVisible        bool           `db:"members_visible"`
+	RejoinCooldownSeconds int            `db:"rejoin_cooldown_seconds"`
+	AutoApproveDomains    pq.StringArray `db:"auto_approve_domains"`
+	RequireJoinMessage    bool           `db:"require_join_message"`
+	RequireVouch          bool           `db:"require_vouch"`
+	WaitlistEnabled       bool           `db:"waitlist_enabled"`
+	AnnouncementOnly      bool           `db:"announcement_only"`
+	SlowModeSeconds       int            `db:"slow_mode_seconds"`
+	HoldNewMemberHours    int            `db:"hold_new_member_hours"`
+	HoldMinApproved       int            `db:"hold_min_approved_messages"`
+	SuccessorID           *int64         `db:"successor_id"`
+	SuccessionRules       pq.StringArray `db:"succession_rules"`
+	OwnerInactiveDays     int            `db:"owner_inactive_days"`
+}
+
+func (gs GroupSettings) RejoinCooldown() time.Duration {
+	return time.Duration(gs.RejoinCooldownSeconds) * time.Second
+}
+
+const groupColumns = `id, name, owner_id, type, max_members, encrypted_group_key, key_nonce, ` +
+	`members_visible, rejoin_cooldown_seconds, auto_approve_domains, require_join_message, require_vouch, waitlist_enabled, ` +
+	`announcement_only, slow_mode_seconds, hold_new_member_hours, hold_min_approved_messages, ` +
+	`successor_id, succession_rules, owner_inactive_days, ` +
+	`description, tags, member_count, last_activity_at, created_at, deleted_at, archived_at, archived_by`
+
+// groupColumnsAs qualifies groupColumns with a table alias for joins.
+func groupColumnsAs(alias string) string {
+	cols := strings.Split(groupColumns, ", ")
+	for i, c := range cols { cols[i] = alias + "." + c }
+	return strings.Join(cols, ", ")
+}
+
+// NewGroup carries the fields set when a group is created.
+type NewGroup struct {
+	Name           string
+	OwnerID        int64
+	Type           string
+	MaxMembers     int
+	EncryptedKey   string
+	KeyNonce       string
+	MembersVisible bool
+	RejoinCooldown time.Duration
+	Description    string
+	Tags           []string
 }
 
 type GroupMember struct {
-	GroupID   int64      `db:"group_id"`
-	UserID    int64      `db:"user_id"`
-	JoinedAt  time.Time  `db:"joined_at"`
-	LastLeftAt *time.Time `db:"last_left_at"`
+	GroupID    int64      `db:"group_id"`
+	UserID     int64      `db:"user_id"`
+	Role       string     `db:"role"`
+	JoinedAt   time.Time  `db:"joined_at"`
+	MutedUntil *time.Time `db:"muted_until"`
+	MuteReason *string    `db:"mute_reason"`
 }
 
-type JoinRequest struct {
-	ID          int64     `db:"id"`
-	GroupID     int64     `db:"group_id"`
-	RequesterID int64     `db:"requester_id"`
-	Status      string    `db:"status"`
-	CreatedAt   time.Time `db:"created_at"`
+// MutedAt reports whether the member is muted at now.
+func (m *GroupMember) MutedAt(now time.Time) bool { return m.MutedUntil != nil && m.MutedUntil.After(now) }
+
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// Member is a group member as shown in member listings.
+type Member struct {
+	UserID      int64      `db:"user_id"`
+	DisplayName string     `db:"display_name"`
+	Role        string     `db:"role"`
+	JoinedAt    time.Time  `db:"joined_at"`
+	MutedUntil  *time.Time `db:"muted_until"`
+}
+
+// MemberFilter narrows ListMembers. Role and Query are optional; After is a
+// keyset cursor over (joined_at, user_id).
+type MemberFilter struct {
+	GroupID int64
+	Role    string
+	Query   string
+	After   *Cursor
+	Limit   int
 }
 
 type Ban struct {
@@ -43,39 +125,175 @@ type Ban struct {
 	CreatedAt time.Time `db:"created_at"`
 }
 
-type GroupStore struct{ db *sqlx.DB }
+type GroupStore struct{ db DBTX }
 
 func NewGroupStore(db *sqlx.DB) *GroupStore { return &GroupStore{db: db} }
 
-func (s *GroupStore) CreateGroup(ctx context.Context, name string, ownerID int64, typ string, maxMembers int, encKey, nonce string) (*Group, error) {
+func (s *GroupStore) CreateGroup(ctx context.Context, in NewGroup) (*Group, error) {
 	g := &Group{}
 	err := s.db.QueryRowxContext(ctx, `
-		INSERT INTO groups (name, owner_id, type, max_members, encrypted_group_key, key_nonce)
-		VALUES ($1,$2,$3,$4,$5,$6)
-		RETURNING id, name, owner_id, type, max_members, encrypted_group_key, key_nonce, created_at, deleted_at
-	`, name, ownerID, typ, maxMembers, encKey, nonce).StructScan(g)
+		INSERT INTO groups (name, owner_id, type, max_members, encrypted_group_key, key_nonce, members_visible, rejoin_cooldown_seconds, description, tags)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		RETURNING `+groupColumns+`
+	`, in.Name, in.OwnerID, in.Type, in.MaxMembers, in.EncryptedKey, in.KeyNonce, in.MembersVisible, int(in.RejoinCooldown.Seconds()), in.Description, pq.StringArray(in.Tags)).StructScan(g)
 	return g, err
 }
 
 func (s *GroupStore) GetGroup(ctx context.Context, id int64) (*Group, error) {
 	g := &Group{}
-	err := s.db.GetContext(ctx, g, `SELECT id, name, owner_id, type, max_members, encrypted_group_key, key_nonce, created_at, deleted_at FROM groups WHERE id=$1 AND deleted_at IS NULL`, id)
+	err := s.db.GetContext(ctx, g, `SELECT `+groupColumns+` FROM groups WHERE id=$1 AND deleted_at IS NULL`, id)
 	return g, err
 }
 
-func (s *GroupStore) ListPublicGroups(ctx context.Context, limit int) ([]Group, error) {
-	if limit <= 0 || limit > 100 { limit = 50 }
-	rows := []Group{}
-	err := s.db.SelectContext(ctx, &rows, `SELECT id, name, owner_id, type, max_members, encrypted_group_key, key_nonce, created_at, deleted_at FROM groups WHERE type='open' AND deleted_at IS NULL ORDER BY id DESC LIMIT $1`, limit)
+// LockGroup reads the group and holds a row lock on it until the surrounding
+// transaction ends. Membership changes lock the group first so capacity and
+// ownership checks cannot race.
+func (s *GroupStore) LockGroup(ctx context.Context, id int64) (*Group, error) {
+	g := &Group{}
+	err := s.db.GetContext(ctx, g, `SELECT `+groupColumns+` FROM groups WHERE id=$1 AND deleted_at IS NULL FOR UPDATE`, id)
+	return g, err
+}
+
+// PublicGroup is an open group annotated with the caller's membership:
+// owner, admin, member, banned or none.
+type PublicGroup struct {
+	Group
+	Membership string `db:"membership"`
+}
+
+// ListPublicGroups pages open groups newest first. After is a cursor over id.
+func (s *GroupStore) ListPublicGroups(ctx context.Context, callerID int64, after *Cursor, limit int) ([]PublicGroup, error) {
+	var afterID int64
+	if after != nil { afterID = after.ID }
+	rows := []PublicGroup{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT `+groupColumnsAs("g")+`,
+			CASE WHEN gm.role IS NOT NULL THEN gm.role
+			     WHEN EXISTS(SELECT 1 FROM bans b WHERE b.group_id=g.id AND b.user_id=$1) THEN 'banned'
+			     ELSE 'none' END AS membership
+		FROM groups g
+		LEFT JOIN group_members gm ON gm.group_id=g.id AND gm.user_id=$1
+		WHERE g.type='open' AND g.deleted_at IS NULL AND ($2=0 OR g.id < $2)
+		ORDER BY g.id DESC LIMIT $3
+	`, callerID, afterID, limit)
 	return rows, err
 }
 
-func (s *GroupStore) ListOwnedGroups(ctx context.Context, ownerID int64) ([]Group, error) {
+// MemberGroup is a group the user belongs to, with their role, unread count
+// and metadata of the latest message (ciphertext is never included).
+type MemberGroup struct {
+	Group
+	Role                string     `db:"role"`
+	JoinedAt            time.Time  `db:"joined_at"`
+	UnreadCount         int        `db:"unread_count"`
+	LastMessageID       *int64     `db:"last_message_id"`
+	LastMessageSenderID *int64     `db:"last_message_sender_id"`
+	LastMessageAt       *time.Time `db:"last_message_at"`
+}
+
+// ListMemberGroups pages the user's groups by last activity, newest first, in
+// a single query. Role optionally narrows to groups where the user has it.
+func (s *GroupStore) ListMemberGroups(ctx context.Context, userID int64, role string, after *Cursor, limit int) ([]MemberGroup, error) {
+	var afterAt *time.Time
+	var afterID int64
+	if after != nil {
+		t := after.Time()
+		afterAt, afterID = &t, after.ID
+	}
+	rows := []MemberGroup{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT `+groupColumnsAs("g")+`, gm.role, gm.joined_at,
+			(SELECT COUNT(*) FROM messages m JOIN channels c ON c.id=m.channel_id
+			 WHERE m.group_id=g.id AND m.status='approved' AND m.id > gm.last_read_message_id AND m.sender_id <> gm.user_id
+			   AND `+channelVisible("c", "gm.user_id", "gm.role")+`) AS unread_count,
+			lm.id AS last_message_id, lm.sender_id AS last_message_sender_id, lm.created_at AS last_message_at
+		FROM group_members gm
+		JOIN groups g ON g.id=gm.group_id AND g.deleted_at IS NULL
+		LEFT JOIN LATERAL (
+			SELECT m.id, m.sender_id, m.created_at FROM messages m JOIN channels c ON c.id=m.channel_id
+			WHERE m.group_id=g.id AND m.status='approved' AND `+channelVisible("c", "gm.user_id", "gm.role")+`
+			ORDER BY m.id DESC LIMIT 1
+		) lm ON true
+		WHERE gm.user_id=$1
+		  AND ($2='' OR gm.role=$2)
+		  AND ($3::timestamptz IS NULL OR (g.last_activity_at, g.id) < ($3, $4))
+		ORDER BY g.last_activity_at DESC, g.id DESC
+		LIMIT $5
+	`, userID, role, afterAt, afterID, limit)
+	return rows, err
+}
+
+// MarkRead advances the member's read marker to messageID, or to the latest
+// message when messageID is 0. The marker never moves backwards.
+func (s *GroupStore) MarkRead(ctx context.Context, groupID, userID, messageID int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE group_members SET last_read_message_id = GREATEST(last_read_message_id,
+			CASE WHEN $3=0 THEN COALESCE((SELECT MAX(id) FROM messages WHERE group_id=$1 AND status='approved'), 0) ELSE $3 END)
+		WHERE group_id=$1 AND user_id=$2
+	`, groupID, userID, messageID)
+	return err
+}
+
+const (
+	SortByMembers  = "members"
+	SortByActivity = "activity"
+)
+
+// GroupSearch filters discovery search over open groups. Query is matched
+// against the full-text vector and trigram similarity of name/description;
+// every tag in Tags must be present. After is a keyset cursor over the sort
+// key (member_count or last_activity_at) and id, both descending.
+type GroupSearch struct {
+	Query    string
+	Tags     []string
+	Sort     string
+	After    *Cursor
+	CallerID int64
+	Limit    int
+}
+
+func (s *GroupStore) SearchGroups(ctx context.Context, f GroupSearch) ([]Group, error) {
+	where := []string{
+		`type='open'`,
+		`deleted_at IS NULL`,
+		`NOT EXISTS (SELECT 1 FROM bans b WHERE b.group_id=groups.id AND b.user_id=$1)`,
+	}
+	args := []interface{}{f.CallerID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	if f.Query != "" {
+		q := arg(f.Query)
+		where = append(where, `(search_vector @@ websearch_to_tsquery('simple', `+q+`) OR name % `+q+` OR description % `+q+`)`)
+	}
+	if len(f.Tags) > 0 {
+		where = append(where, `tags @> `+arg(pq.StringArray(f.Tags)))
+	}
+	order := `member_count DESC, id DESC`
+	if f.Sort == SortByActivity {
+		order = `last_activity_at DESC, id DESC`
+		if f.After != nil { where = append(where, `(last_activity_at, id) < (`+arg(f.After.Time())+`, `+arg(f.After.ID)+`)`) }
+	} else if f.After != nil {
+		where = append(where, `(member_count, id) < (`+arg(f.After.Key)+`, `+arg(f.After.ID)+`)`)
+	}
 	rows := []Group{}
-	err := s.db.SelectContext(ctx, &rows, `SELECT id, name, owner_id, type, max_members, encrypted_group_key, key_nonce, created_at, deleted_at FROM groups WHERE owner_id=$1 AND deleted_at IS NULL ORDER BY id DESC`, ownerID)
+	err := s.db.SelectContext(ctx, &rows, `SELECT `+groupColumns+` FROM groups WHERE `+strings.Join(where, " AND ")+` ORDER BY `+order+` LIMIT `+arg(f.Limit), args...)
 	return rows, err
 }
 
+func (s *GroupStore) UpdateSettings(ctx context.Context, groupID int64, gs GroupSettings) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE groups SET members_visible=$2, rejoin_cooldown_seconds=$3, auto_approve_domains=$4, require_join_message=$5, require_vouch=$6,
+			waitlist_enabled=$7, successor_id=$8, succession_rules=$9, owner_inactive_days=$10, announcement_only=$11,
+			slow_mode_seconds=$12, hold_new_member_hours=$13, hold_min_approved_messages=$14
+		WHERE id=$1
+	`, groupID, gs.MembersVisible, gs.RejoinCooldownSeconds, gs.AutoApproveDomains, gs.RequireJoinMessage, gs.RequireVouch,
+		gs.WaitlistEnabled, gs.SuccessorID, gs.SuccessionRules, gs.OwnerInactiveDays, gs.AnnouncementOnly, gs.SlowModeSeconds,
+		gs.HoldNewMemberHours, gs.HoldMinApproved)
+	return err
+}
+
 func (s *GroupStore) CountMembers(ctx context.Context, groupID int64) (int, error) {
 	var n int
 	err := s.db.GetContext(ctx, &n, `SELECT COUNT(*) FROM group_members WHERE group_id=$1`, groupID)
@@ -88,26 +306,93 @@ func (s *GroupStore) IsMember(ctx context.Context, groupID, userID int64) (bool,
 	return exists, err
 }
 
-func (s *GroupStore) AddMember(ctx context.Context, groupID, userID int64) error {
-	_, err := s.db.ExecContext(ctx, `INSERT INTO group_members (group_id, user_id) VALUES ($1,$2) ON CONFLICT (group_id, user_id) DO NOTHING`, groupID, userID)
+func (s *GroupStore) AddMember(ctx context.Context, groupID, userID int64, role string) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO group_members (group_id, user_id, role) VALUES ($1,$2,$3) ON CONFLICT (group_id, user_id) DO NOTHING`, groupID, userID, role)
 	return err
 }
 
-func (s *GroupStore) RemoveMember(ctx context.Context, groupID, userID int64) error {
-	_, err := s.db.ExecContext(ctx, `DELETE FROM group_members WHERE group_id=$1 AND user_id=$2`, groupID, userID)
+// GetMemberRole returns the caller's role in the group, or "" if not a member.
+func (s *GroupStore) GetMemberRole(ctx context.Context, groupID, userID int64) (string, error) {
+	var role string
+	err := s.db.GetContext(ctx, &role, `SELECT role FROM group_members WHERE group_id=$1 AND user_id=$2`, groupID, userID)
+	if errors.Is(err, sql.ErrNoRows) { return "", nil }
+	return role, err
+}
+
+// GetMember returns the user's membership row, or nil if not a member.
+func (s *GroupStore) GetMember(ctx context.Context, groupID, userID int64) (*GroupMember, error) {
+	m := &GroupMember{}
+	err := s.db.GetContext(ctx, m, `
+		SELECT group_id, user_id, role, joined_at, muted_until, mute_reason FROM group_members WHERE group_id=$1 AND user_id=$2
+	`, groupID, userID)
+	if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+	return m, err
+}
+
+// SetMute mutes the member until the given time, or lifts the mute when
+// until is nil.
+func (s *GroupStore) SetMute(ctx context.Context, groupID, userID, mutedBy int64, until *time.Time, reason *string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE group_members SET muted_until=$3, mute_reason=$4, muted_by=NULLIF($5,0)
+		WHERE group_id=$1 AND user_id=$2
+	`, groupID, userID, until, reason, mutedBy)
 	return err
 }
 
-func (s *GroupStore) UpdateLastLeft(ctx context.Context, groupID, userID int64, t time.Time) error {
-	res, err := s.db.ExecContext(ctx, `UPDATE group_members SET last_left_at=$3 WHERE group_id=$1 AND user_id=$2`, groupID, userID, t)
+func (s *GroupStore) SetMemberRole(ctx context.Context, groupID, userID int64, role string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE group_members SET role=$3 WHERE group_id=$1 AND user_id=$2`, groupID, userID, role)
 	if err != nil { return err }
-	affected, _ := res.RowsAffected()
-	if affected == 0 {
-		// if not a member row exists, create a tombstone row? We'll ignore.
-	}
+	if n, _ := res.RowsAffected(); n == 0 { return sql.ErrNoRows }
 	return nil
 }
 
+func (s *GroupStore) ListMembers(ctx context.Context, f MemberFilter) ([]Member, error) {
+	var afterAt *time.Time
+	var afterID int64
+	if f.After != nil {
+		t := f.After.Time()
+		afterAt, afterID = &t, f.After.ID
+	}
+	rows := []Member{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT gm.user_id, u.display_name, gm.role, gm.joined_at,
+			CASE WHEN gm.muted_until > now() THEN gm.muted_until END AS muted_until
+		FROM group_members gm JOIN users u ON u.id=gm.user_id
+		WHERE gm.group_id=$1
+		  AND ($2='' OR gm.role=$2)
+		  AND ($3='' OR u.display_name ILIKE $3)
+		  AND ($4::timestamptz IS NULL OR (gm.joined_at, gm.user_id) > ($4, $5))
+		ORDER BY gm.joined_at, gm.user_id
+		LIMIT $6
+	`, f.GroupID, f.Role, containsPattern(f.Query), afterAt, afterID, f.Limit)
+	return rows, err
+}
+
+// CountMembersByRole returns member counts keyed by role.
+func (s *GroupStore) CountMembersByRole(ctx context.Context, groupID int64) (map[string]int, error) {
+	rows := []struct {
+		Role  string `db:"role"`
+		Count int    `db:"n"`
+	}{}
+	if err := s.db.SelectContext(ctx, &rows, `SELECT role, COUNT(*) AS n FROM group_members WHERE group_id=$1 GROUP BY role`, groupID); err != nil {
+		return nil, err
+	}
+	out := map[string]int{RoleOwner: 0, RoleAdmin: 0, RoleMember: 0}
+	for _, r := range rows { out[r.Role] = r.Count }
+	return out, nil
+}
+
+// containsPattern builds an ILIKE substring pattern with wildcards in q escaped.
+func containsPattern(q string) string {
+	if q == "" { return "" }
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(q) + "%"
+}
+
+func (s *GroupStore) RemoveMember(ctx context.Context, groupID, userID int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM group_members WHERE group_id=$1 AND user_id=$2`, groupID, userID)
+	return err
+}
+
 func (s *GroupStore) OwnerLeaveAllowed(ctx context.Context, groupID int64) (bool, error) {
 	var n int
 	err := s.db.GetContext(ctx, &n, `SELECT COUNT(*) FROM group_members WHERE group_id=$1`, groupID)
@@ -115,8 +400,25 @@ func (s *GroupStore) OwnerLeaveAllowed(ctx context.Context, groupID int64) (bool
 	return n <= 1, nil
 }
 
+// TransferOwner makes newOwnerID the owner; the previous owner stays on as
+// an admin.
 func (s *GroupStore) TransferOwner(ctx context.Context, groupID, newOwnerID int64) error {
 	_, err := s.db.ExecContext(ctx, `UPDATE groups SET owner_id=$2 WHERE id=$1`, groupID, newOwnerID)
+	if err != nil { return err }
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE group_members SET role = CASE WHEN user_id=$2 THEN 'owner' ELSE 'admin' END
+		WHERE group_id=$1 AND (user_id=$2 OR role='owner')
+	`, groupID, newOwnerID)
+	return err
+}
+
+// SetArchived archives the group on behalf of by, or unarchives it when
+// archived is false.
+func (s *GroupStore) SetArchived(ctx context.Context, groupID, by int64, archived bool) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE groups SET archived_at = CASE WHEN $3 THEN now() END, archived_by = CASE WHEN $3 THEN $2::bigint END
+		WHERE id=$1
+	`, groupID, by, archived)
 	return err
 }
 
@@ -131,57 +433,45 @@ func (s *GroupStore) IsBanned(ctx context.Context, groupID, userID int64) (bool,
 	return exists, err
 }
 
+// LockBan locks the user's ban row for the rest of the transaction,
+// returning sql.ErrNoRows if the user is not banned.
+func (s *GroupStore) LockBan(ctx context.Context, groupID, userID int64) error {
+	var one int
+	return s.db.GetContext(ctx, &one, `SELECT 1 FROM bans WHERE group_id=$1 AND user_id=$2 FOR UPDATE`, groupID, userID)
+}
+
 func (s *GroupStore) AddBan(ctx context.Context, groupID, userID int64, reason *string) error {
 	_, err := s.db.ExecContext(ctx, `INSERT INTO bans (group_id, user_id, reason) VALUES ($1,$2,$3) ON CONFLICT (group_id, user_id) DO NOTHING`, groupID, userID, reason)
 	return err
 }
 
-func (s *GroupStore) GetLastLeft(ctx context.Context, groupID, userID int64) (*time.Time, error) {
-	gm := &GroupMember{}
-	err := s.db.GetContext(ctx, gm, `SELECT group_id, user_id, joined_at, last_left_at FROM group_members WHERE group_id=$1 AND user_id=$2`, groupID, userID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) { return nil, nil }
-		return nil, err
-	}
-	return gm.LastLeftAt, nil
-}
-
-func (s *GroupStore) CreateJoinRequest(ctx context.Context, groupID, userID int64) (*JoinRequest, error) {
-	jr := &JoinRequest{}
-	err := s.db.QueryRowxContext(ctx, `
-		INSERT INTO join_requests (group_id, requester_id, status)
-		VALUES ($1,$2,'pending')
-		RETURNING id, group_id, requester_id, status, created_at
-	`, groupID, userID).StructScan(jr)
-	return jr, err
-}
-
-func (s *GroupStore) ListPendingJoinRequests(ctx context.Context, groupID int64) ([]JoinRequest, error) {
-	rows := []JoinRequest{}
-	err := s.db.SelectContext(ctx, &rows, `
-		SELECT id, group_id, requester_id, status, created_at
-		FROM join_requests WHERE group_id=$1 AND status='pending'
-		ORDER BY created_at ASC
-	`, groupID)
-	return rows, err
+// RemoveBan lifts a ban, returning sql.ErrNoRows if none existed.
+func (s *GroupStore) RemoveBan(ctx context.Context, groupID, userID int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM bans WHERE group_id=$1 AND user_id=$2`, groupID, userID)
+	if err != nil { return err }
+	if n, _ := res.RowsAffected(); n == 0 { return sql.ErrNoRows }
+	return nil
 }
 
-func (s *GroupStore) GetJoinRequestByID(ctx context.Context, id int64) (*JoinRequest, error) {
-	jr := &JoinRequest{}
-	err := s.db.GetContext(ctx, jr, `SELECT id, group_id, requester_id, status, created_at FROM join_requests WHERE id=$1`, id)
-	return jr, err
+func (s *GroupStore) AddVouch(ctx context.Context, groupID, userID, voucherID int64) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO group_vouches (group_id, user_id, voucher_id) VALUES ($1,$2,$3) ON CONFLICT DO NOTHING`, groupID, userID, voucherID)
+	return err
 }
 
-func (s *GroupStore) SetJoinRequestStatus(ctx context.Context, id int64, status string) error {
-	_, err := s.db.ExecContext(ctx, `UPDATE join_requests SET status=$2 WHERE id=$1`, id, status)
+func (s *GroupStore) RemoveVouch(ctx context.Context, groupID, userID, voucherID int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM group_vouches WHERE group_id=$1 AND user_id=$2 AND voucher_id=$3`, groupID, userID, voucherID)
 	return err
 }
 
-func (s *GroupStore) ApproveJoinRequest(ctx context.Context, id int64) error {
-	_, err := s.db.ExecContext(ctx, `UPDATE join_requests SET status='approved' WHERE id=$1`, id)
-	if err != nil { return err }
-	jr := &JoinRequest{}
-	err = s.db.GetContext(ctx, jr, `SELECT group_id, requester_id FROM join_requests WHERE id=$1`, id)
-	if err != nil { return err }
-	return s.AddMember(ctx, jr.GroupID, jr.RequesterID)
+// HasVouch reports whether a current member of the group vouches for userID.
+func (s *GroupStore) HasVouch(ctx context.Context, groupID, userID int64) (bool, error) {
+	var exists bool
+	err := s.db.GetContext(ctx, &exists, `
+		SELECT EXISTS(
+			SELECT 1 FROM group_vouches v
+			JOIN group_members gm ON gm.group_id=v.group_id AND gm.user_id=v.voucher_id
+			WHERE v.group_id=$1 AND v.user_id=$2
+		)
+	`, groupID, userID)
+	return exists, err
 }
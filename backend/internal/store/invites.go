@@ -0,0 +1,128 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Invite is a direct invitation (InviteeID set) or an invite link (Token
+// set) to a group. Invites bypass join policies but not bans or capacity.
+type Invite struct {
+	ID         int64      `db:"id"`
+	GroupID    int64      `db:"group_id"`
+	InviterID  *int64     `db:"inviter_id"`
+	InviteeID  *int64     `db:"invitee_id"`
+	Token      *string    `db:"token"`
+	MaxUses    *int       `db:"max_uses"`
+	Uses       int        `db:"uses"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	AcceptedAt *time.Time `db:"accepted_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+const inviteColumns = `id, group_id, inviter_id, invitee_id, token, max_uses, uses, expires_at, accepted_at, revoked_at, created_at`
+
+// Usable reports whether the invite can still be redeemed at now.
+func (i *Invite) Usable(now time.Time) bool {
+	if i.RevokedAt != nil || i.AcceptedAt != nil || !i.ExpiresAt.After(now) { return false }
+	return i.MaxUses == nil || i.Uses < *i.MaxUses
+}
+
+// UserInvite is a direct invitation as listed for its invitee.
+type UserInvite struct {
+	Invite
+	GroupName string `db:"group_name"`
+}
+
+// CreateDirectInvite invites the user, refreshing the inviter and expiry of
+// an invitation that is still open.
+func (s *GroupStore) CreateDirectInvite(ctx context.Context, groupID, inviterID, inviteeID int64, expiresAt time.Time) (*Invite, error) {
+	inv := &Invite{}
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO group_invites (group_id, inviter_id, invitee_id, expires_at) VALUES ($1,$2,$3,$4)
+		ON CONFLICT (group_id, invitee_id) WHERE invitee_id IS NOT NULL AND accepted_at IS NULL AND revoked_at IS NULL
+		DO UPDATE SET inviter_id=EXCLUDED.inviter_id, expires_at=EXCLUDED.expires_at
+		RETURNING `+inviteColumns+`
+	`, groupID, inviterID, inviteeID, expiresAt).StructScan(inv)
+	return inv, err
+}
+
+func (s *GroupStore) CreateInviteLink(ctx context.Context, groupID, inviterID int64, token string, maxUses *int, expiresAt time.Time) (*Invite, error) {
+	inv := &Invite{}
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO group_invites (group_id, inviter_id, token, max_uses, expires_at) VALUES ($1,$2,$3,$4,$5)
+		RETURNING `+inviteColumns+`
+	`, groupID, inviterID, token, maxUses, expiresAt).StructScan(inv)
+	return inv, err
+}
+
+// ListGroupInvites returns the group's invites and links that can still be
+// redeemed, newest first.
+func (s *GroupStore) ListGroupInvites(ctx context.Context, groupID int64) ([]Invite, error) {
+	rows := []Invite{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT `+inviteColumns+` FROM group_invites
+		WHERE group_id=$1 AND revoked_at IS NULL AND accepted_at IS NULL AND expires_at > now()
+		  AND (max_uses IS NULL OR uses < max_uses)
+		ORDER BY id DESC
+	`, groupID)
+	return rows, err
+}
+
+// ListUserInvites returns the user's open direct invitations to live groups.
+func (s *GroupStore) ListUserInvites(ctx context.Context, userID int64) ([]UserInvite, error) {
+	rows := []UserInvite{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT i.id, i.group_id, i.inviter_id, i.invitee_id, i.token, i.max_uses, i.uses, i.expires_at, i.accepted_at,
+			i.revoked_at, i.created_at, g.name AS group_name
+		FROM group_invites i JOIN groups g ON g.id=i.group_id AND g.deleted_at IS NULL
+		WHERE i.invitee_id=$1 AND i.revoked_at IS NULL AND i.accepted_at IS NULL AND i.expires_at > now()
+		ORDER BY i.id DESC
+	`, userID)
+	return rows, err
+}
+
+// LockInvite reads an invite with a row lock, or nil if there is none.
+func (s *GroupStore) LockInvite(ctx context.Context, id int64) (*Invite, error) {
+	inv := &Invite{}
+	err := s.db.GetContext(ctx, inv, `SELECT `+inviteColumns+` FROM group_invites WHERE id=$1 FOR UPDATE`, id)
+	if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+	return inv, err
+}
+
+// LockInviteByToken reads an invite link with a row lock, or nil if there is
+// none.
+func (s *GroupStore) LockInviteByToken(ctx context.Context, token string) (*Invite, error) {
+	inv := &Invite{}
+	err := s.db.GetContext(ctx, inv, `SELECT `+inviteColumns+` FROM group_invites WHERE token=$1 FOR UPDATE`, token)
+	if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+	return inv, err
+}
+
+// UseInvite counts a redemption; direct invitations are consumed by it.
+func (s *GroupStore) UseInvite(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE group_invites SET uses=uses+1, accepted_at = CASE WHEN invitee_id IS NOT NULL THEN now() END
+		WHERE id=$1
+	`, id)
+	return err
+}
+
+func (s *GroupStore) RevokeInvite(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE group_invites SET revoked_at=now() WHERE id=$1 AND revoked_at IS NULL`, id)
+	return err
+}
+
+// HiddenFrom reports whether the group is secret and userID is not a member,
+// in which case the group must look nonexistent to them.
+func (s *GroupStore) HiddenFrom(ctx context.Context, groupID, userID int64) (bool, error) {
+	var hidden bool
+	err := s.db.GetContext(ctx, &hidden, `
+		SELECT EXISTS(SELECT 1 FROM groups g WHERE g.id=$1 AND g.type='secret' AND g.deleted_at IS NULL
+			AND NOT EXISTS(SELECT 1 FROM group_members gm WHERE gm.group_id=g.id AND gm.user_id=$2))
+	`, groupID, userID)
+	return hidden, err
+}
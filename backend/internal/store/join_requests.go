@@ -0,0 +1,177 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	JoinPending   = "pending"
+	JoinApproved  = "approved"
+	JoinDeclined  = "declined"
+	JoinCancelled = "cancelled"
+	JoinExpired   = "expired"
+)
+
+type JoinRequest struct {
+	ID             int64       `db:"id"`
+	GroupID        int64       `db:"group_id"`
+	RequesterID    int64       `db:"requester_id"`
+	Status         string      `db:"status"`
+	Message        string      `db:"message"`
+	Answers        JoinAnswers `db:"answers"`
+	DecisionReason *string     `db:"decision_reason"`
+	DecidedBy      *int64      `db:"decided_by"`
+	DecidedAt      *time.Time  `db:"decided_at"`
+	ExpiresAt      time.Time   `db:"expires_at"`
+	CreatedAt      time.Time   `db:"created_at"`
+}
+
+const joinRequestColumns = `id, group_id, requester_id, status, message, answers, decision_reason, decided_by, decided_at, expires_at, created_at`
+
+// NewJoinRequest carries the fields set when a join request is filed.
+type NewJoinRequest struct {
+	GroupID     int64
+	RequesterID int64
+	Message     string
+	Answers     JoinAnswers
+	ExpiresAt   time.Time
+}
+
+// UserJoinRequest is a join request as listed for its requester.
+type UserJoinRequest struct {
+	JoinRequest
+	GroupName string `db:"group_name"`
+}
+
+func (s *GroupStore) CreateJoinRequest(ctx context.Context, in NewJoinRequest) (*JoinRequest, error) {
+	jr := &JoinRequest{}
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO join_requests (group_id, requester_id, status, message, answers, expires_at)
+		VALUES ($1,$2,'pending',$3,$4,$5)
+		RETURNING `+joinRequestColumns+`
+	`, in.GroupID, in.RequesterID, in.Message, in.Answers, in.ExpiresAt).StructScan(jr)
+	return jr, err
+}
+
+// GetPendingJoinRequest returns the user's live pending request for the
+// group, or nil if there is none.
+func (s *GroupStore) GetPendingJoinRequest(ctx context.Context, groupID, userID int64) (*JoinRequest, error) {
+	jr := &JoinRequest{}
+	err := s.db.GetContext(ctx, jr, `
+		SELECT `+joinRequestColumns+` FROM join_requests
+		WHERE group_id=$1 AND requester_id=$2 AND status='pending' AND expires_at > now()
+	`, groupID, userID)
+	if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+	return jr, err
+}
+
+func (s *GroupStore) ListPendingJoinRequests(ctx context.Context, groupID int64) ([]JoinRequest, error) {
+	rows := []JoinRequest{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT `+joinRequestColumns+`
+		FROM join_requests WHERE group_id=$1 AND status='pending' AND expires_at > now()
+		ORDER BY created_at ASC
+	`, groupID)
+	return rows, err
+}
+
+// ListUserJoinRequests pages a requester's join requests newest first,
+// optionally narrowed to one status. After is a cursor over id.
+func (s *GroupStore) ListUserJoinRequests(ctx context.Context, userID int64, status string, after *Cursor, limit int) ([]UserJoinRequest, error) {
+	var afterID int64
+	if after != nil { afterID = after.ID }
+	rows := []UserJoinRequest{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT jr.id, jr.group_id, jr.requester_id, jr.status, jr.message, jr.answers, jr.decision_reason, jr.decided_by,
+			jr.decided_at, jr.expires_at, jr.created_at, g.name AS group_name
+		FROM join_requests jr JOIN groups g ON g.id=jr.group_id
+		WHERE jr.requester_id=$1 AND ($2='' OR jr.status=$2) AND ($3=0 OR jr.id < $3)
+		ORDER BY jr.id DESC LIMIT $4
+	`, userID, status, afterID, limit)
+	return rows, err
+}
+
+// JoinRequestFilter selects a group's join requests for bulk decisions.
+// With IDs set, those requests are returned whatever their status so the
+// caller can report on each; otherwise live pending requests matching the
+// optional created_at bounds and requester email domain are returned.
+type JoinRequestFilter struct {
+	IDs           []int64
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	EmailDomain   string
+}
+
+// LockJoinRequests reads and row-locks the group's requests matching f,
+// oldest first.
+func (s *GroupStore) LockJoinRequests(ctx context.Context, groupID int64, f JoinRequestFilter, limit int) ([]JoinRequest, error) {
+	rows := []JoinRequest{}
+	if len(f.IDs) > 0 {
+		err := s.db.SelectContext(ctx, &rows, `
+			SELECT `+joinRequestColumns+` FROM join_requests
+			WHERE group_id=$1 AND id = ANY($2)
+			ORDER BY created_at, id LIMIT $3 FOR UPDATE
+		`, groupID, pq.Int64Array(f.IDs), limit)
+		return rows, err
+	}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT jr.id, jr.group_id, jr.requester_id, jr.status, jr.message, jr.answers, jr.decision_reason, jr.decided_by,
+			jr.decided_at, jr.expires_at, jr.created_at
+		FROM join_requests jr JOIN users u ON u.id=jr.requester_id
+		WHERE jr.group_id=$1 AND jr.status='pending' AND jr.expires_at > now()
+		  AND ($2::timestamptz IS NULL OR jr.created_at > $2)
+		  AND ($3::timestamptz IS NULL OR jr.created_at < $3)
+		  AND ($4='' OR lower(split_part(u.email, '@', 2)) = $4)
+		ORDER BY jr.created_at, jr.id LIMIT $5 FOR UPDATE OF jr
+	`, groupID, f.CreatedAfter, f.CreatedBefore, f.EmailDomain, limit)
+	return rows, err
+}
+
+func (s *GroupStore) GetJoinRequestByID(ctx context.Context, id int64) (*JoinRequest, error) {
+	jr := &JoinRequest{}
+	err := s.db.GetContext(ctx, jr, `SELECT `+joinRequestColumns+` FROM join_requests WHERE id=$1`, id)
+	return jr, err
+}
+
+// LockJoinRequest reads a join request with a row lock for the transaction.
+func (s *GroupStore) LockJoinRequest(ctx context.Context, id int64) (*JoinRequest, error) {
+	jr := &JoinRequest{}
+	err := s.db.GetContext(ctx, jr, `SELECT `+joinRequestColumns+` FROM join_requests WHERE id=$1 FOR UPDATE`, id)
+	return jr, err
+}
+
+// DecideJoinRequest moves a request out of pending. decidedBy is 0 when the
+// system (expiry) or the requester (cancellation) made the change.
+func (s *GroupStore) DecideJoinRequest(ctx context.Context, id int64, status string, decidedBy int64, reason *string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE join_requests SET status=$2, decided_by=NULLIF($3,0), decision_reason=$4, decided_at=now()
+		WHERE id=$1
+	`, id, status, decidedBy, reason)
+	return err
+}
+
+// ExpireJoinRequests marks pending requests past their expiry as expired and
+// returns how many were changed.
+func (s *GroupStore) ExpireJoinRequests(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE join_requests SET status='expired', decided_at=now()
+		WHERE status='pending' AND expires_at <= now()
+	`)
+	if err != nil { return 0, err }
+	return res.RowsAffected()
+}
+
+// ExpireLapsedJoinRequest expires the user's lapsed pending request for one
+// group, so a new request can be filed before the expiry job has run.
+func (s *GroupStore) ExpireLapsedJoinRequest(ctx context.Context, groupID, userID int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE join_requests SET status='expired', decided_at=now()
+		WHERE group_id=$1 AND requester_id=$2 AND status='pending' AND expires_at <= now()
+	`, groupID, userID)
+	return err
+}
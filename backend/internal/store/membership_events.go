@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	EventJoined      = "joined"
+	EventLeft        = "left"
+	EventBanished    = "banished"
+	EventUnbanned    = "unbanned"
+	EventApproved    = "approved"
+	EventRoleChanged = "role_changed"
+	EventMuted       = "muted"
+	EventUnmuted     = "unmuted"
+)
+
+// MembershipEvent is one transition in a user's membership of a group.
+// ActorID is who caused it; Role is the new role for role_changed events.
+type MembershipEvent struct {
+	ID        int64     `db:"id"`
+	GroupID   int64     `db:"group_id"`
+	UserID    int64     `db:"user_id"`
+	ActorID   *int64    `db:"actor_id"`
+	Event     string    `db:"event"`
+	Role      *string   `db:"role"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (s *GroupStore) RecordMembershipEvent(ctx context.Context, groupID, userID, actorID int64, event string, role *string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO membership_events (group_id, user_id, actor_id, event, role)
+		VALUES ($1,$2,NULLIF($3,0),$4,$5)
+	`, groupID, userID, actorID, event, role)
+	return err
+}
+
+// ListMembershipEvents pages a group's timeline newest first, optionally for
+// one user. After is a cursor over id.
+func (s *GroupStore) ListMembershipEvents(ctx context.Context, groupID, userID int64, after *Cursor, limit int) ([]MembershipEvent, error) {
+	var afterID int64
+	if after != nil { afterID = after.ID }
+	rows := []MembershipEvent{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT id, group_id, user_id, actor_id, event, role, created_at
+		FROM membership_events
+		WHERE group_id=$1 AND ($2=0 OR user_id=$2) AND ($3=0 OR id < $3)
+		ORDER BY id DESC LIMIT $4
+	`, groupID, userID, afterID, limit)
+	return rows, err
+}
+
+// LastDepartureAt returns when the user last left, was banished from, or had
+// a ban lifted in the group, or nil if never. Rejoin cooldowns run from it.
+func (s *GroupStore) LastDepartureAt(ctx context.Context, groupID, userID int64) (*time.Time, error) {
+	var t time.Time
+	err := s.db.GetContext(ctx, &t, `
+		SELECT created_at FROM membership_events
+		WHERE group_id=$1 AND user_id=$2 AND event IN ('left','banished','unbanned')
+		ORDER BY created_at DESC LIMIT 1
+	`, groupID, userID)
+	if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+	if err != nil { return nil, err }
+	return &t, nil
+}
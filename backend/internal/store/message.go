@@ -7,44 +7,101 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// Message review states. Held messages await a moderator; only approved
+// messages are visible to the whole channel.
+const (
+	MessageApproved = "approved"
+	MessageHeld     = "held"
+	MessageRejected = "rejected"
+)
+
 type Message struct {
-	ID        int64     `db:"id"`
-	GroupID   int64     `db:"group_id"`
-	SenderID  int64     `db:"sender_id"`
-	Ciphertext string   `db:"ciphertext"`
-	IV        string    `db:"iv"`
-	CreatedAt time.Time `db:"created_at"`
+	ID         int64     `db:"id"`
+	GroupID    int64     `db:"group_id"`
+	ChannelID  int64     `db:"channel_id"`
+	SenderID   int64     `db:"sender_id"`
+	Ciphertext string    `db:"ciphertext"`
+	IV         string    `db:"iv"`
+	Status     string    `db:"status"`
+	CreatedAt  time.Time `db:"created_at"`
 }
 
-type MessageStore struct{ db *sqlx.DB }
+const messageColumns = `id, group_id, channel_id, sender_id, ciphertext, iv, status, created_at`
+
+type MessageStore struct{ db DBTX }
 
 func NewMessageStore(db *sqlx.DB) *MessageStore { return &MessageStore{db: db} }
 
-func (s *MessageStore) Create(ctx context.Context, groupID, senderID int64, ciphertext, iv string) (*Message, error) {
+func (s *MessageStore) Create(ctx context.Context, groupID, channelID, senderID int64, ciphertext, iv, status string) (*Message, error) {
 	m := &Message{}
 	err := s.db.QueryRowxContext(ctx, `
-		INSERT INTO messages (group_id, sender_id, ciphertext, iv)
-		VALUES ($1,$2,$3,$4)
-		RETURNING id, group_id, sender_id, ciphertext, iv, created_at
-	`, groupID, senderID, ciphertext, iv).StructScan(m)
+		INSERT INTO messages (group_id, channel_id, sender_id, ciphertext, iv, status)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		RETURNING `+messageColumns+`
+	`, groupID, channelID, senderID, ciphertext, iv, status).StructScan(m)
 	return m, err
 }
 
-func (s *MessageStore) List(ctx context.Context, groupID int64, limit int, before *time.Time) ([]Message, error) {
+// List pages a channel's messages newest first. Besides approved messages,
+// viewers see their own held and rejected messages, and moderators see
+// everyone's held messages.
+func (s *MessageStore) List(ctx context.Context, channelID, viewerID int64, moderator bool, limit int, before *time.Time) ([]Message, error) {
 	if limit <= 0 || limit > 100 { limit = 50 }
 	msgs := []Message{}
-	if before != nil {
-		err := s.db.SelectContext(ctx, &msgs, `
-			SELECT id, group_id, sender_id, ciphertext, iv, created_at
-			FROM messages WHERE group_id=$1 AND created_at < $2
-			ORDER BY created_at DESC LIMIT $3
-		`, groupID, *before, limit)
-		return msgs, err
-	}
 	err := s.db.SelectContext(ctx, &msgs, `
-		SELECT id, group_id, sender_id, ciphertext, iv, created_at
-		FROM messages WHERE group_id=$1
-		ORDER BY created_at DESC LIMIT $2
-	`, groupID, limit)
+		SELECT `+messageColumns+`
+		FROM messages
+		WHERE channel_id=$1 AND ($4::timestamptz IS NULL OR created_at < $4)
+		  AND (status='approved' OR sender_id=$2 OR ($3 AND status='held'))
+		ORDER BY created_at DESC LIMIT $5
+	`, channelID, viewerID, moderator, before, limit)
+	return msgs, err
+}
+
+// Get returns the message, or nil if there is none.
+func (s *MessageStore) Get(ctx context.Context, id int64) (*Message, error) {
+	rows := []Message{}
+	err := s.db.SelectContext(ctx, &rows, `SELECT `+messageColumns+` FROM messages WHERE id=$1`, id)
+	if err != nil || len(rows) == 0 { return nil, err }
+	return &rows[0], nil
+}
+
+func (s *MessageStore) Delete(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM messages WHERE id=$1`, id)
+	return err
+}
+
+// CountApproved returns how many of the sender's messages in the group have
+// been published.
+func (s *MessageStore) CountApproved(ctx context.Context, groupID, senderID int64) (int, error) {
+	var n int
+	err := s.db.GetContext(ctx, &n, `SELECT COUNT(*) FROM messages WHERE group_id=$1 AND sender_id=$2 AND status='approved'`, groupID, senderID)
+	return n, err
+}
+
+// ListHeld pages the group's review queue oldest first. After is a cursor
+// over id.
+func (s *MessageStore) ListHeld(ctx context.Context, groupID int64, after *Cursor, limit int) ([]Message, error) {
+	var afterID int64
+	if after != nil { afterID = after.ID }
+	msgs := []Message{}
+	err := s.db.SelectContext(ctx, &msgs, `
+		SELECT `+messageColumns+`
+		FROM messages WHERE group_id=$1 AND status='held' AND id > $2
+		ORDER BY id LIMIT $3
+	`, groupID, afterID, limit)
 	return msgs, err
 }
+
+// ReviewHeld moves a held message to status. It returns nil if the message
+// is not held in this group, so concurrent reviews decide it only once.
+func (s *MessageStore) ReviewHeld(ctx context.Context, groupID, messageID, reviewerID int64, status string, reason *string) (*Message, error) {
+	rows := []Message{}
+	err := s.db.SelectContext(ctx, &rows, `
+		UPDATE messages SET status=$4, reviewed_by=$3, reviewed_at=now(), review_reason=$5
+		WHERE id=$2 AND group_id=$1 AND status='held'
+		RETURNING `+messageColumns+`
+	`, groupID, messageID, reviewerID, status, reason)
+	if err != nil || len(rows) == 0 { return nil, err }
+	return &rows[0], nil
+}
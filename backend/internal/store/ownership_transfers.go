@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferDeclined  = "declined"
+	TransferCancelled = "cancelled"
+	TransferExpired   = "expired"
+)
+
+// TransferOffered is the audit event for a new offer; the other audit
+// events share the status names.
+const TransferOffered = "offered"
+
+// OwnershipTransfer is an offer of a group's ownership to one of its members.
+type OwnershipTransfer struct {
+	ID         int64      `db:"id"`
+	GroupID    int64      `db:"group_id"`
+	FromUserID *int64     `db:"from_user_id"`
+	ToUserID   int64      `db:"to_user_id"`
+	Status     string     `db:"status"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	DecidedAt  *time.Time `db:"decided_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+const ownershipTransferColumns = `id, group_id, from_user_id, to_user_id, status, expires_at, decided_at, created_at`
+
+// OwnershipTransferEvent is one step in a transfer's audit trail.
+type OwnershipTransferEvent struct {
+	ID         int64     `db:"id"`
+	TransferID int64     `db:"transfer_id"`
+	GroupID    int64     `db:"group_id"`
+	ActorID    *int64    `db:"actor_id"`
+	Event      string    `db:"event"`
+	ToUserID   int64     `db:"to_user_id"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func (s *GroupStore) CreateOwnershipTransfer(ctx context.Context, groupID, fromUserID, toUserID int64, expiresAt time.Time) (*OwnershipTransfer, error) {
+	t := &OwnershipTransfer{}
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO ownership_transfers (group_id, from_user_id, to_user_id, status, expires_at)
+		VALUES ($1,$2,$3,'pending',$4)
+		RETURNING `+ownershipTransferColumns+`
+	`, groupID, fromUserID, toUserID, expiresAt).StructScan(t)
+	return t, err
+}
+
+// GetPendingOwnershipTransfer returns the group's live pending transfer, or
+// nil if there is none.
+func (s *GroupStore) GetPendingOwnershipTransfer(ctx context.Context, groupID int64) (*OwnershipTransfer, error) {
+	t := &OwnershipTransfer{}
+	err := s.db.GetContext(ctx, t, `
+		SELECT `+ownershipTransferColumns+` FROM ownership_transfers
+		WHERE group_id=$1 AND status='pending' AND expires_at > now()
+	`, groupID)
+	if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+	return t, err
+}
+
+// DecideOwnershipTransfer moves a transfer out of pending.
+func (s *GroupStore) DecideOwnershipTransfer(ctx context.Context, id int64, status string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE ownership_transfers SET status=$2, decided_at=now() WHERE id=$1`, id, status)
+	return err
+}
+
+// CancelPendingOwnershipTransfer withdraws the group's pending transfer, if
+// any, returning it so the caller can record the step.
+func (s *GroupStore) CancelPendingOwnershipTransfer(ctx context.Context, groupID int64) (*OwnershipTransfer, error) {
+	t := &OwnershipTransfer{}
+	err := s.db.QueryRowxContext(ctx, `
+		UPDATE ownership_transfers SET status='cancelled', decided_at=now()
+		WHERE group_id=$1 AND status='pending'
+		RETURNING `+ownershipTransferColumns+`
+	`, groupID).StructScan(t)
+	if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+	return t, err
+}
+
+// ExpireOwnershipTransfers marks lapsed pending transfers as expired and
+// records the step for each; it returns how many were changed.
+func (s *GroupStore) ExpireOwnershipTransfers(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		WITH expired AS (
+			UPDATE ownership_transfers SET status='expired', decided_at=now()
+			WHERE status='pending' AND expires_at <= now()
+			RETURNING id, group_id
+		)
+		INSERT INTO ownership_transfer_events (transfer_id, group_id, event)
+		SELECT id, group_id, 'expired' FROM expired
+	`)
+	if err != nil { return 0, err }
+	return res.RowsAffected()
+}
+
+// ExpireLapsedOwnershipTransfer expires the group's lapsed pending transfer,
+// so a new one can be offered before the expiry job has run.
+func (s *GroupStore) ExpireLapsedOwnershipTransfer(ctx context.Context, groupID int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		WITH expired AS (
+			UPDATE ownership_transfers SET status='expired', decided_at=now()
+			WHERE group_id=$1 AND status='pending' AND expires_at <= now()
+			RETURNING id, group_id
+		)
+		INSERT INTO ownership_transfer_events (transfer_id, group_id, event)
+		SELECT id, group_id, 'expired' FROM expired
+	`, groupID)
+	return err
+}
+
+func (s *GroupStore) RecordOwnershipTransferEvent(ctx context.Context, t *OwnershipTransfer, actorID int64, event string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO ownership_transfer_events (transfer_id, group_id, actor_id, event)
+		VALUES ($1,$2,NULLIF($3,0),$4)
+	`, t.ID, t.GroupID, actorID, event)
+	return err
+}
+
+// ListOwnershipTransferEvents pages a group's transfer audit trail newest
+// first. After is a cursor over id.
+func (s *GroupStore) ListOwnershipTransferEvents(ctx context.Context, groupID int64, after *Cursor, limit int) ([]OwnershipTransferEvent, error) {
+	var afterID int64
+	if after != nil { afterID = after.ID }
+	rows := []OwnershipTransferEvent{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT e.id, e.transfer_id, e.group_id, e.actor_id, e.event, t.to_user_id, e.created_at
+		FROM ownership_transfer_events e JOIN ownership_transfers t ON t.id=e.transfer_id
+		WHERE e.group_id=$1 AND ($2=0 OR e.id < $2)
+		ORDER BY e.id DESC LIMIT $3
+	`, groupID, afterID, limit)
+	return rows, err
+}
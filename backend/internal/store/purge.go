@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// GroupPurge is the audit record left behind when a group is hard-deleted.
+type GroupPurge struct {
+	ID           int64     `db:"id"`
+	GroupID      int64     `db:"group_id"`
+	Name         string    `db:"name"`
+	OwnerID      int64     `db:"owner_id"`
+	DeletedAt    time.Time `db:"deleted_at"`
+	MemberCount  int       `db:"member_count"`
+	MessageCount int64     `db:"message_count"`
+	PurgedAt     time.Time `db:"purged_at"`
+}
+
+// LockDeletedGroup reads a soft-deleted group with a row lock.
+func (s *GroupStore) LockDeletedGroup(ctx context.Context, id int64) (*Group, error) {
+	g := &Group{}
+	err := s.db.GetContext(ctx, g, `SELECT `+groupColumns+` FROM groups WHERE id=$1 AND deleted_at IS NOT NULL FOR UPDATE`, id)
+	return g, err
+}
+
+func (s *GroupStore) RestoreGroup(ctx context.Context, groupID int64) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE groups SET deleted_at=NULL WHERE id=$1`, groupID)
+	return err
+}
+
+// ListPurgeableGroups returns up to limit ids of groups deleted before cutoff.
+func (s *GroupStore) ListPurgeableGroups(ctx context.Context, cutoff time.Time, limit int) ([]int64, error) {
+	ids := []int64{}
+	err := s.db.SelectContext(ctx, &ids, `
+		SELECT id FROM groups WHERE deleted_at IS NOT NULL AND deleted_at < $1
+		ORDER BY deleted_at LIMIT $2
+	`, cutoff, limit)
+	return ids, err
+}
+
+// PurgeGroup records a purge audit row for g and hard-deletes it; members,
+// messages, requests and the wrapped group key go with it by cascade.
+func (s *GroupStore) PurgeGroup(ctx context.Context, g *Group) (*GroupPurge, error) {
+	p := &GroupPurge{}
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO group_purges (group_id, name, owner_id, deleted_at, member_count, message_count)
+		VALUES ($1,$2,$3,$4,
+			(SELECT COUNT(*) FROM group_members WHERE group_id=$1),
+			(SELECT COUNT(*) FROM messages WHERE group_id=$1))
+		RETURNING id, group_id, name, owner_id, deleted_at, member_count, message_count, purged_at
+	`, g.ID, g.Name, g.OwnerID, g.DeletedAt).StructScan(p)
+	if err != nil { return nil, err }
+	_, err = s.db.ExecContext(ctx, `DELETE FROM groups WHERE id=$1`, g.ID)
+	return p, err
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	QuestionText   = "text"
+	QuestionChoice = "choice"
+)
+
+// Question is a screening question shown to applicants of a private group.
+type Question struct {
+	ID        int64          `db:"id"`
+	GroupID   int64          `db:"group_id"`
+	Prompt    string         `db:"prompt"`
+	Kind      string         `db:"kind"`
+	Options   pq.StringArray `db:"options"`
+	Required  bool           `db:"required"`
+	Position  int            `db:"position"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+// JoinAnswer is an applicant's answer, with the prompt copied as it was
+// asked so later edits to the question set don't change history.
+type JoinAnswer struct {
+	QuestionID int64  `json:"question_id"`
+	Prompt     string `json:"prompt"`
+	Answer     string `json:"answer"`
+}
+
+// JoinAnswers is stored as a JSONB array on join_requests.
+type JoinAnswers []JoinAnswer
+
+func (a JoinAnswers) Value() (driver.Value, error) {
+	if a == nil { a = JoinAnswers{} }
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+func (a *JoinAnswers) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	case nil:
+		*a = JoinAnswers{}
+		return nil
+	}
+	return errors.New("unsupported type for JoinAnswers")
+}
+
+func (s *GroupStore) ListQuestions(ctx context.Context, groupID int64) ([]Question, error) {
+	rows := []Question{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT id, group_id, prompt, kind, options, required, position, created_at
+		FROM group_questions WHERE group_id=$1 ORDER BY position, id
+	`, groupID)
+	return rows, err
+}
+
+// ReplaceQuestions swaps the group's question set for qs, in order. Run it
+// inside a transaction.
+func (s *GroupStore) ReplaceQuestions(ctx context.Context, groupID int64, qs []Question) ([]Question, error) {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM group_questions WHERE group_id=$1`, groupID); err != nil { return nil, err }
+	out := make([]Question, 0, len(qs))
+	for i, q := range qs {
+		row := Question{}
+		err := s.db.QueryRowxContext(ctx, `
+			INSERT INTO group_questions (group_id, prompt, kind, options, required, position)
+			VALUES ($1,$2,$3,$4,$5,$6)
+			RETURNING id, group_id, prompt, kind, options, required, position, created_at
+		`, groupID, q.Prompt, q.Kind, pq.StringArray(q.Options), q.Required, i).StructScan(&row)
+		if err != nil { return nil, err }
+		out = append(out, row)
+	}
+	return out, nil
+}
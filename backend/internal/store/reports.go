@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Report targets.
+const (
+	ReportMessage = "message"
+	ReportUser    = "user"
+	ReportGroup   = "group"
+)
+
+const (
+	ReportOpen     = "open"
+	ReportResolved = "resolved"
+)
+
+// Report resolutions.
+const (
+	ResolveDismiss       = "dismiss"
+	ResolveDeleteMessage = "delete_message"
+	ResolveBanish        = "banish"
+	ResolveSuspend       = "suspend"
+)
+
+// ErrDuplicateReport is returned when the reporter already has an open
+// report on the same target.
+var ErrDuplicateReport = errors.New("you have already reported this")
+
+// Report is an abuse report. UserID is the reported user, or the sender of a
+// reported message; GroupID is where the reported content lives, if anywhere.
+type Report struct {
+	ID             int64      `db:"id"`
+	ReporterID     int64      `db:"reporter_id"`
+	TargetType     string     `db:"target_type"`
+	MessageID      *int64     `db:"message_id"`
+	UserID         *int64     `db:"user_id"`
+	GroupID        *int64     `db:"group_id"`
+	Category       string     `db:"category"`
+	Comment        *string    `db:"comment"`
+	Status         string     `db:"status"`
+	Resolution     *string    `db:"resolution"`
+	ResolutionNote *string    `db:"resolution_note"`
+	ResolvedBy     *int64     `db:"resolved_by"`
+	ResolvedAt     *time.Time `db:"resolved_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+}
+
+const reportColumns = `id, reporter_id, target_type, message_id, user_id, group_id, category, comment, status, ` +
+	`resolution, resolution_note, resolved_by, resolved_at, created_at`
+
+// ReportFilter narrows a report queue. GroupID 0 spans all groups;
+// ContentOnly leaves out reports against groups themselves.
+type ReportFilter struct {
+	GroupID     int64
+	Status      string
+	TargetType  string
+	ContentOnly bool
+}
+
+type ReportStore struct{ db DBTX }
+
+func NewReportStore(db *sqlx.DB) *ReportStore { return &ReportStore{db: db} }
+
+func (s *ReportStore) Create(ctx context.Context, r Report) (*Report, error) {
+	out := &Report{}
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO reports (reporter_id, target_type, message_id, user_id, group_id, category, comment)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+		RETURNING `+reportColumns+`
+	`, r.ReporterID, r.TargetType, r.MessageID, r.UserID, r.GroupID, r.Category, r.Comment).StructScan(out)
+	if isUniqueViolation(err) { return nil, ErrDuplicateReport }
+	return out, err
+}
+
+// Lock returns the report locked for resolution, or nil if there is none.
+func (s *ReportStore) Lock(ctx context.Context, id int64) (*Report, error) {
+	r := &Report{}
+	err := s.db.GetContext(ctx, r, `SELECT `+reportColumns+` FROM reports WHERE id=$1 FOR UPDATE`, id)
+	if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+	return r, err
+}
+
+// List pages a report queue newest first. After is a cursor over id.
+func (s *ReportStore) List(ctx context.Context, f ReportFilter, after *Cursor, limit int) ([]Report, error) {
+	var afterID int64
+	if after != nil { afterID = after.ID }
+	rows := []Report{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT `+reportColumns+` FROM reports
+		WHERE ($1=0 OR group_id=$1) AND ($2='' OR status=$2) AND ($3='' OR target_type=$3) AND ($4=0 OR id < $4)
+			AND (NOT $6 OR target_type <> 'group')
+		ORDER BY id DESC LIMIT $5
+	`, f.GroupID, f.Status, f.TargetType, afterID, limit, f.ContentOnly)
+	return rows, err
+}
+
+func (s *ReportStore) Resolve(ctx context.Context, id, resolverID int64, resolution string, note *string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE reports SET status='resolved', resolution=$3, resolution_note=$4, resolved_by=$2, resolved_at=now()
+		WHERE id=$1
+	`, id, resolverID, resolution, note)
+	return err
+}
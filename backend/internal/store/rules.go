@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// GroupRules is one version of a group's rules document.
+type GroupRules struct {
+	GroupID   int64     `db:"group_id"`
+	Version   int       `db:"version"`
+	Body      string    `db:"body"`
+	CreatedBy *int64    `db:"created_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+const groupRulesColumns = `group_id, version, body, created_by, created_at`
+
+// GetRules returns the given version of the group's rules, or the latest
+// when version is 0; nil if there is none.
+func (s *GroupStore) GetRules(ctx context.Context, groupID int64, version int) (*GroupRules, error) {
+	rows := []GroupRules{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT `+groupRulesColumns+` FROM group_rules
+		WHERE group_id=$1 AND ($2=0 OR version=$2)
+		ORDER BY version DESC LIMIT 1
+	`, groupID, version)
+	if err != nil || len(rows) == 0 { return nil, err }
+	return &rows[0], nil
+}
+
+// PublishRules adds the next version of the group's rules. The caller holds
+// the group lock so versions don't collide.
+func (s *GroupStore) PublishRules(ctx context.Context, groupID, createdBy int64, body string) (*GroupRules, error) {
+	r := &GroupRules{}
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO group_rules (group_id, version, body, created_by)
+		VALUES ($1, (SELECT COALESCE(MAX(version),0)+1 FROM group_rules WHERE group_id=$1), $2, $3)
+		RETURNING `+groupRulesColumns+`
+	`, groupID, body, createdBy).StructScan(r)
+	return r, err
+}
+
+func (s *GroupStore) AcceptRules(ctx context.Context, groupID, userID int64, version int) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO group_rules_acceptances (group_id, version, user_id) VALUES ($1,$2,$3)
+		ON CONFLICT DO NOTHING
+	`, groupID, version, userID)
+	return err
+}
+
+// AcceptedRulesVersion returns the latest rules version the user has
+// accepted in the group, or 0.
+func (s *GroupStore) AcceptedRulesVersion(ctx context.Context, groupID, userID int64) (int, error) {
+	var v int
+	err := s.db.GetContext(ctx, &v, `
+		SELECT COALESCE(MAX(version),0) FROM group_rules_acceptances WHERE group_id=$1 AND user_id=$2
+	`, groupID, userID)
+	return v, err
+}
+
+// PendingRulesVersion returns the group's current rules version if the user
+// has yet to accept it, or 0 when there is nothing to accept.
+func (s *GroupStore) PendingRulesVersion(ctx context.Context, groupID, userID int64) (int, error) {
+	rows := []int{}
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT r.version FROM group_rules r
+		WHERE r.group_id=$1 AND r.body <> ''
+		  AND r.version = (SELECT MAX(version) FROM group_rules WHERE group_id=$1)
+		  AND NOT EXISTS (SELECT 1 FROM group_rules_acceptances a WHERE a.group_id=$1 AND a.version=r.version AND a.user_id=$2)
+	`, groupID, userID)
+	if err != nil || len(rows) == 0 { return 0, err }
+	return rows[0], nil
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ownerNeedsSuccession matches groups whose owner is deactivated or has been
+// inactive longer than the group allows; it expects groups g joined to the
+// owner as u.
+const ownerNeedsSuccession = `(u.deactivated_at IS NOT NULL OR
+	(g.owner_inactive_days > 0 AND u.last_seen_at < now() - g.owner_inactive_days * interval '1 day'))`
+
+// ListGroupsNeedingSuccession pages ids of live groups whose owner should be
+// replaced, in id order after afterID.
+func (s *GroupStore) ListGroupsNeedingSuccession(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	ids := []int64{}
+	err := s.db.SelectContext(ctx, &ids, `
+		SELECT g.id FROM groups g JOIN users u ON u.id=g.owner_id
+		WHERE g.deleted_at IS NULL AND g.id > $1 AND `+ownerNeedsSuccession+`
+		ORDER BY g.id LIMIT $2
+	`, afterID, limit)
+	return ids, err
+}
+
+// OwnerNeedsSuccession re-checks one group, for use under the group lock.
+func (s *GroupStore) OwnerNeedsSuccession(ctx context.Context, groupID int64) (bool, error) {
+	var needs bool
+	err := s.db.GetContext(ctx, &needs, `
+		SELECT EXISTS(SELECT 1 FROM groups g JOIN users u ON u.id=g.owner_id WHERE g.id=$1 AND `+ownerNeedsSuccession+`)
+	`, groupID)
+	return needs, err
+}
+
+// IsActiveMember reports whether the user is a member with an open,
+// unsuspended account.
+func (s *GroupStore) IsActiveMember(ctx context.Context, groupID, userID int64) (bool, error) {
+	var exists bool
+	err := s.db.GetContext(ctx, &exists, `
+		SELECT EXISTS(SELECT 1 FROM group_members gm JOIN users u ON u.id=gm.user_id
+			WHERE gm.group_id=$1 AND gm.user_id=$2 AND u.deactivated_at IS NULL AND u.suspended_at IS NULL)
+	`, groupID, userID)
+	return exists, err
+}
+
+// LongestTenuredMember returns the earliest-joined member with the given role
+// and an open, unsuspended account, other than exclude, or 0 if there is
+// none.
+func (s *GroupStore) LongestTenuredMember(ctx context.Context, groupID int64, role string, exclude int64) (int64, error) {
+	var id int64
+	err := s.db.GetContext(ctx, &id, `
+		SELECT gm.user_id FROM group_members gm JOIN users u ON u.id=gm.user_id
+		WHERE gm.group_id=$1 AND gm.role=$2 AND gm.user_id <> $3 AND u.deactivated_at IS NULL AND u.suspended_at IS NULL
+		ORDER BY gm.joined_at, gm.user_id LIMIT 1
+	`, groupID, role, exclude)
+	if errors.Is(err, sql.ErrNoRows) { return 0, nil }
+	return id, err
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// SendLimits bounds how often one member may post in a group. A zero
+// SlowMode or BurstLimit disables that check; BurstWindow must be positive
+// when BurstLimit is set.
+type SendLimits struct {
+	SlowMode    time.Duration
+	BurstLimit  int
+	BurstWindow time.Duration
+}
+
+// TakeSendSlot records a send by userID if limits allow it. The check and
+// the update are a single statement, so concurrent sends on any replica
+// cannot both pass. When the send is refused it returns how long the caller
+// must wait.
+func (s *MessageStore) TakeSendSlot(ctx context.Context, groupID, userID int64, lim SendLimits) (time.Duration, error) {
+	var ok bool
+	err := s.db.QueryRowxContext(ctx, `
+		INSERT INTO send_throttle (group_id, user_id, last_sent_at, window_started_at, window_count)
+		VALUES ($1, $2, now(), now(), 1)
+		ON CONFLICT (group_id, user_id) DO UPDATE SET
+			last_sent_at = now(),
+			window_started_at = CASE WHEN send_throttle.window_started_at <= now() - make_interval(secs => $5::int)
+				THEN now() ELSE send_throttle.window_started_at END,
+			window_count = CASE WHEN send_throttle.window_started_at <= now() - make_interval(secs => $5::int)
+				THEN 1 ELSE send_throttle.window_count + 1 END
+		WHERE ($3::int = 0 OR send_throttle.last_sent_at <= now() - make_interval(secs => $3::int))
+			AND ($4::int = 0 OR send_throttle.window_count < $4
+				OR send_throttle.window_started_at <= now() - make_interval(secs => $5::int))
+		RETURNING true
+	`, groupID, userID, int(lim.SlowMode/time.Second), lim.BurstLimit, int(lim.BurstWindow/time.Second)).Scan(&ok)
+	if err == nil { return 0, nil }
+	if !errors.Is(err, sql.ErrNoRows) { return 0, err }
+
+	var st struct {
+		LastSentAt      time.Time `db:"last_sent_at"`
+		WindowStartedAt time.Time `db:"window_started_at"`
+		WindowCount     int       `db:"window_count"`
+		Now             time.Time `db:"now"`
+	}
+	err = s.db.QueryRowxContext(ctx, `
+		SELECT last_sent_at, window_started_at, window_count, now() AS now
+		FROM send_throttle WHERE group_id=$1 AND user_id=$2
+	`, groupID, userID).StructScan(&st)
+	if err != nil { return 0, err }
+	var wait time.Duration
+	if lim.SlowMode > 0 {
+		if d := st.LastSentAt.Add(lim.SlowMode).Sub(st.Now); d > wait { wait = d }
+	}
+	if lim.BurstLimit > 0 && st.WindowCount >= lim.BurstLimit {
+		if d := st.WindowStartedAt.Add(lim.BurstWindow).Sub(st.Now); d > wait { wait = d }
+	}
+	// The window may have rolled over between the two statements.
+	if wait <= 0 { wait = time.Second }
+	return wait, nil
+}
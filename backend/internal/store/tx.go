@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DBTX is the query surface shared by *sqlx.DB and *sqlx.Tx, so stores can
+// run either against the pool or inside a transaction.
+type DBTX interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// Tx exposes stores bound to a single database transaction.
+type Tx struct {
+	Groups   *GroupStore
+	Users    *UserStore
+	Messages *MessageStore
+	Reports  *ReportStore
+	Admin    *AdminStore
+}
+
+func newTx(q DBTX) *Tx {
+	return &Tx{
+		Groups:   &GroupStore{db: q},
+		Users:    &UserStore{db: q},
+		Messages: &MessageStore{db: q},
+		Reports:  &ReportStore{db: q},
+		Admin:    &AdminStore{db: q},
+	}
+}
+
+// UnitOfWork runs functions inside a transaction.
+type UnitOfWork struct{ db *sqlx.DB }
+
+func NewUnitOfWork(db *sqlx.DB) *UnitOfWork { return &UnitOfWork{db: db} }
+
+// Do begins a transaction, passes fn stores bound to it, and commits if fn
+// returns nil. Any error or panic rolls the transaction back.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(tx *Tx) error) (err error) {
+	sqlTx, err := u.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil { return err }
+	defer func() {
+		if p := recover(); p != nil {
+			_ = sqlTx.Rollback()
+			panic(p)
+		}
+		if err != nil { _ = sqlTx.Rollback() }
+	}()
+	if err = fn(newTx(sqlTx)); err != nil { return err }
+	return sqlTx.Commit()
+}
@@ -8,12 +8,20 @@ import (
 )
 
 type User struct {
-	ID           int64     `db:"id"`
-	Email        string    `db:"email"`
-	PasswordHash string    `db:"password_hash"`
-	CreatedAt    time.Time `db:"created_at"`
+	ID               int64      `db:"id"`
+	Email            string     `db:"email"`
+	DisplayName      string     `db:"display_name"`
+	PasswordHash     string     `db:"password_hash"`
+	LastSeenAt       time.Time  `db:"last_seen_at"`
+	DeactivatedAt    *time.Time `db:"deactivated_at"`
+	IsAdmin          bool       `db:"is_admin"`
+	SuspendedAt      *time.Time `db:"suspended_at"`
+	SuspensionReason *string    `db:"suspension_reason"`
+	CreatedAt        time.Time  `db:"created_at"`
 }
 
+const userColumns = `id, email, display_name, password_hash, last_seen_at, deactivated_at, is_admin, suspended_at, suspension_reason, created_at`
+
 type RefreshToken struct {
 	ID        int64     `db:"id"`
 	UserID    int64     `db:"user_id"`
@@ -22,31 +30,106 @@ type RefreshToken struct {
 	CreatedAt time.Time `db:"created_at"`
 }
 
-type UserStore struct{ db *sqlx.DB }
+type UserStore struct{ db DBTX }
 
 func NewUserStore(db *sqlx.DB) *UserStore { return &UserStore{db: db} }
 
-func (s *UserStore) CreateUser(ctx context.Context, email, passwordHash string) (*User, error) {
+func (s *UserStore) CreateUser(ctx context.Context, email, displayName, passwordHash string) (*User, error) {
 	u := &User{}
 	err := s.db.QueryRowxContext(ctx,
-		`INSERT INTO users (email, password_hash) VALUES ($1, $2) RETURNING id, email, password_hash, created_at`,
-		email, passwordHash,
+		`INSERT INTO users (email, display_name, password_hash) VALUES ($1, $2, $3) RETURNING `+userColumns,
+		email, displayName, passwordHash,
 	).StructScan(u)
 	return u, err
 }
 
 func (s *UserStore) GetUserByEmail(ctx context.Context, email string) (*User, error) {
 	u := &User{}
-	err := s.db.GetContext(ctx, u, `SELECT id, email, password_hash, created_at FROM users WHERE email=$1`, email)
+	err := s.db.GetContext(ctx, u, `SELECT `+userColumns+` FROM users WHERE email=$1`, email)
 	return u, err
 }
 
 func (s *UserStore) GetUserByID(ctx context.Context, id int64) (*User, error) {
 	u := &User{}
-	err := s.db.GetContext(ctx, u, `SELECT id, email, password_hash, created_at FROM users WHERE id=$1`, id)
+	err := s.db.GetContext(ctx, u, `SELECT `+userColumns+` FROM users WHERE id=$1`, id)
+	return u, err
+}
+
+// LockUser returns the user row locked for update.
+func (s *UserStore) LockUser(ctx context.Context, id int64) (*User, error) {
+	u := &User{}
+	err := s.db.GetContext(ctx, u, `SELECT `+userColumns+` FROM users WHERE id=$1 FOR UPDATE`, id)
 	return u, err
 }
 
+// TouchLastSeen records account activity; inactive owners are replaced by
+// the succession job.
+func (s *UserStore) TouchLastSeen(ctx context.Context, userID int64) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE users SET last_seen_at=now() WHERE id=$1`, userID)
+	return err
+}
+
+// Deactivate closes the account and revokes its refresh tokens.
+func (s *UserStore) Deactivate(ctx context.Context, userID int64) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE users SET deactivated_at=now() WHERE id=$1 AND deactivated_at IS NULL`, userID); err != nil { return err }
+	_, err := s.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE user_id=$1`, userID)
+	return err
+}
+
+// Suspend blocks the account from signing in and revokes its refresh tokens
+// in one statement.
+func (s *UserStore) Suspend(ctx context.Context, userID int64, reason *string) error {
+	_, err := s.db.ExecContext(ctx, `
+		WITH suspended AS (
+			UPDATE users SET suspended_at=now(), suspension_reason=$2 WHERE id=$1 AND suspended_at IS NULL
+		)
+		DELETE FROM refresh_tokens WHERE user_id=$1
+	`, userID, reason)
+	return err
+}
+
+// Unsuspend lets a suspended account sign in again.
+func (s *UserStore) Unsuspend(ctx context.Context, userID int64) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE users SET suspended_at=NULL, suspension_reason=NULL WHERE id=$1`, userID)
+	return err
+}
+
+// AccountStatus reports whether the account is deactivated or suspended;
+// the access token check runs it on every authenticated request.
+func (s *UserStore) AccountStatus(ctx context.Context, userID int64) (deactivated, suspended bool, err error) {
+	var st struct {
+		Deactivated bool `db:"deactivated"`
+		Suspended   bool `db:"suspended"`
+	}
+	err = s.db.GetContext(ctx, &st, `
+		SELECT deactivated_at IS NOT NULL AS deactivated, suspended_at IS NOT NULL AS suspended FROM users WHERE id=$1
+	`, userID)
+	return st.Deactivated, st.Suspended, err
+}
+
+// UserSearch filters the admin user listing. Query matches email or display
+// name; Suspended limits it to suspended accounts.
+type UserSearch struct {
+	Query     string
+	Suspended bool
+	After     *Cursor
+	Limit     int
+}
+
+// SearchUsers pages accounts by id.
+func (s *UserStore) SearchUsers(ctx context.Context, f UserSearch) ([]User, error) {
+	var afterID int64
+	if f.After != nil { afterID = f.After.ID }
+	rows := []User{}
+	pattern := containsPattern(f.Query)
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT `+userColumns+` FROM users
+		WHERE ($1='' OR email ILIKE $1 OR display_name ILIKE $1) AND (NOT $2 OR suspended_at IS NOT NULL) AND id > $3
+		ORDER BY id LIMIT $4
+	`, pattern, f.Suspended, afterID, f.Limit)
+	return rows, err
+}
+
 func (s *UserStore) CreateRefreshToken(ctx context.Context, userID int64, token string, expiresAt time.Time) error {
 	_, err := s.db.ExecContext(ctx, `INSERT INTO refresh_tokens (user_id, token, expires_at) VALUES ($1, $2, $3)`, userID, token, expiresAt)
 	return err
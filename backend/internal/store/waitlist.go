@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type WaitlistEntry struct {
+	ID        int64     `db:"id"`
+	GroupID   int64     `db:"group_id"`
+	UserID    int64     `db:"user_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// AddToWaitlist queues the user and returns their 1-based position. Queuing
+// again keeps the original place.
+func (s *GroupStore) AddToWaitlist(ctx context.Context, groupID, userID int64) (int, error) {
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO group_waitlist (group_id, user_id) VALUES ($1,$2) ON CONFLICT (group_id, user_id) DO NOTHING`, groupID, userID); err != nil {
+		return 0, err
+	}
+	return s.WaitlistPosition(ctx, groupID, userID)
+}
+
+// WaitlistPosition returns the user's 1-based place in the queue, or 0 if
+// they are not waiting.
+func (s *GroupStore) WaitlistPosition(ctx context.Context, groupID, userID int64) (int, error) {
+	var pos int
+	err := s.db.GetContext(ctx, &pos, `
+		SELECT (SELECT COUNT(*) FROM group_waitlist w2 WHERE w2.group_id=w.group_id AND w2.id <= w.id)
+		FROM group_waitlist w WHERE w.group_id=$1 AND w.user_id=$2
+	`, groupID, userID)
+	if errors.Is(err, sql.ErrNoRows) { return 0, nil }
+	return pos, err
+}
+
+func (s *GroupStore) WaitlistLength(ctx context.Context, groupID int64) (int, error) {
+	var n int
+	err := s.db.GetContext(ctx, &n, `SELECT COUNT(*) FROM group_waitlist WHERE group_id=$1`, groupID)
+	return n, err
+}
+
+func (s *GroupStore) RemoveFromWaitlist(ctx context.Context, groupID, userID int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM group_waitlist WHERE group_id=$1 AND user_id=$2`, groupID, userID)
+	return err
+}
+
+// PopWaitlist removes and returns the head of the queue, or nil if empty.
+func (s *GroupStore) PopWaitlist(ctx context.Context, groupID int64) (*WaitlistEntry, error) {
+	e := &WaitlistEntry{}
+	err := s.db.QueryRowxContext(ctx, `
+		DELETE FROM group_waitlist WHERE id = (
+			SELECT id FROM group_waitlist WHERE group_id=$1 ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, group_id, user_id, created_at
+	`, groupID).StructScan(e)
+	if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+	return e, err
+}
@@ -0,0 +1,4 @@
+ALTER TABLE groups DROP COLUMN IF EXISTS members_visible;
+DROP INDEX IF EXISTS idx_group_members_group_joined;
+ALTER TABLE group_members DROP COLUMN IF EXISTS role;
+ALTER TABLE users DROP COLUMN IF EXISTS display_name;
@@ -0,0 +1,10 @@
+-- Display names for member listings
+ALTER TABLE users ADD COLUMN IF NOT EXISTS display_name TEXT NOT NULL DEFAULT '';
+
+-- Member roles (owner mirrors groups.owner_id)
+ALTER TABLE group_members ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner','admin','member'));
+UPDATE group_members gm SET role='owner' FROM groups g WHERE g.id=gm.group_id AND g.owner_id=gm.user_id;
+CREATE INDEX IF NOT EXISTS idx_group_members_group_joined ON group_members(group_id, joined_at, user_id);
+
+-- Whether non-members may list members of an open group
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS members_visible BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS trg_messages_activity ON messages;
+DROP FUNCTION IF EXISTS groups_touch_activity();
+DROP TRIGGER IF EXISTS trg_group_members_count ON group_members;
+DROP FUNCTION IF EXISTS groups_sync_member_count();
+DROP INDEX IF EXISTS idx_groups_open_activity;
+DROP INDEX IF EXISTS idx_groups_open_members;
+DROP INDEX IF EXISTS idx_groups_tags;
+DROP INDEX IF EXISTS idx_groups_description_trgm;
+DROP INDEX IF EXISTS idx_groups_name_trgm;
+DROP INDEX IF EXISTS idx_groups_search;
+ALTER TABLE groups DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE groups DROP COLUMN IF EXISTS last_activity_at;
+ALTER TABLE groups DROP COLUMN IF EXISTS member_count;
+ALTER TABLE groups DROP COLUMN IF EXISTS tags;
+ALTER TABLE groups DROP COLUMN IF EXISTS description;
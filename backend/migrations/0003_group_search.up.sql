@@ -0,0 +1,45 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Discovery metadata
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS member_count INT NOT NULL DEFAULT 0;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', name), 'A') || setweight(to_tsvector('simple', description), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_groups_search ON groups USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_groups_name_trgm ON groups USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_groups_description_trgm ON groups USING GIN (description gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_groups_tags ON groups USING GIN (tags);
+CREATE INDEX IF NOT EXISTS idx_groups_open_members ON groups(member_count DESC, id DESC) WHERE type='open' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_groups_open_activity ON groups(last_activity_at DESC, id DESC) WHERE type='open' AND deleted_at IS NULL;
+
+-- Keep member_count in step with group_members
+CREATE OR REPLACE FUNCTION groups_sync_member_count() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE groups SET member_count = member_count + 1 WHERE id = NEW.group_id;
+    ELSIF TG_OP = 'DELETE' THEN
+        UPDATE groups SET member_count = member_count - 1 WHERE id = OLD.group_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS trg_group_members_count ON group_members;
+CREATE TRIGGER trg_group_members_count AFTER INSERT OR DELETE ON group_members
+    FOR EACH ROW EXECUTE FUNCTION groups_sync_member_count();
+UPDATE groups g SET member_count = (SELECT COUNT(*) FROM group_members gm WHERE gm.group_id = g.id);
+
+-- Bump last_activity_at on every message
+CREATE OR REPLACE FUNCTION groups_touch_activity() RETURNS trigger AS $$
+BEGIN
+    UPDATE groups SET last_activity_at = NEW.created_at WHERE id = NEW.group_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS trg_messages_activity ON messages;
+CREATE TRIGGER trg_messages_activity AFTER INSERT ON messages
+    FOR EACH ROW EXECUTE FUNCTION groups_touch_activity();
+UPDATE groups g SET last_activity_at = COALESCE((SELECT MAX(created_at) FROM messages m WHERE m.group_id = g.id), g.created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_groups_open_id;
+DROP INDEX IF EXISTS idx_messages_group_id;
+ALTER TABLE group_members DROP COLUMN IF EXISTS last_read_message_id;
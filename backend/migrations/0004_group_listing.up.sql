@@ -0,0 +1,4 @@
+-- Read markers for unread counts
+ALTER TABLE group_members ADD COLUMN IF NOT EXISTS last_read_message_id BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_messages_group_id ON messages(group_id, id);
+CREATE INDEX IF NOT EXISTS idx_groups_open_id ON groups(id DESC) WHERE type='open' AND deleted_at IS NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE group_members ADD COLUMN IF NOT EXISTS last_left_at TIMESTAMPTZ;
+DROP INDEX IF EXISTS idx_membership_events_user;
+DROP INDEX IF EXISTS idx_membership_events_group;
+DROP TABLE IF EXISTS membership_events;
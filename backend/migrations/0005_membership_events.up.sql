@@ -0,0 +1,18 @@
+-- Membership history: one row per transition
+CREATE TABLE IF NOT EXISTS membership_events (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    event TEXT NOT NULL CHECK (event IN ('joined','left','banished','approved','role_changed')),
+    role TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_membership_events_group ON membership_events(group_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_membership_events_user ON membership_events(group_id, user_id, created_at DESC);
+
+INSERT INTO membership_events (group_id, user_id, actor_id, event, role, created_at)
+SELECT group_id, user_id, user_id, 'joined', role, joined_at FROM group_members;
+
+-- Departures are now read from membership_events
+ALTER TABLE group_members DROP COLUMN IF EXISTS last_left_at;
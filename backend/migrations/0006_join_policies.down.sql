@@ -0,0 +1,10 @@
+DELETE FROM membership_events WHERE event = 'unbanned';
+ALTER TABLE membership_events DROP CONSTRAINT IF EXISTS membership_events_event_check;
+ALTER TABLE membership_events ADD CONSTRAINT membership_events_event_check
+    CHECK (event IN ('joined','left','banished','approved','role_changed'));
+DROP TABLE IF EXISTS group_vouches;
+ALTER TABLE join_requests DROP COLUMN IF EXISTS message;
+ALTER TABLE groups DROP COLUMN IF EXISTS require_vouch;
+ALTER TABLE groups DROP COLUMN IF EXISTS require_join_message;
+ALTER TABLE groups DROP COLUMN IF EXISTS auto_approve_domains;
+ALTER TABLE groups DROP COLUMN IF EXISTS rejoin_cooldown_seconds;
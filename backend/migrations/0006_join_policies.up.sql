@@ -0,0 +1,24 @@
+-- Per-group rejoin cooldown (previously a fixed 48h for private groups)
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS rejoin_cooldown_seconds INT NOT NULL DEFAULT 0 CHECK (rejoin_cooldown_seconds >= 0);
+UPDATE groups SET rejoin_cooldown_seconds = 172800 WHERE type = 'private';
+
+-- Join policies
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS auto_approve_domains TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS require_join_message BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS require_vouch BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE join_requests ADD COLUMN IF NOT EXISTS message TEXT NOT NULL DEFAULT '';
+
+-- A member vouching for a prospective member
+CREATE TABLE IF NOT EXISTS group_vouches (
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    voucher_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (group_id, user_id, voucher_id)
+);
+
+-- Lifting a ban starts the rejoin cooldown as well
+ALTER TABLE membership_events DROP CONSTRAINT IF EXISTS membership_events_event_check;
+ALTER TABLE membership_events ADD CONSTRAINT membership_events_event_check
+    CHECK (event IN ('joined','left','banished','unbanned','approved','role_changed'));
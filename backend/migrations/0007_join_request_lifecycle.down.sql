@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_join_requests_expiry;
+DROP INDEX IF EXISTS idx_join_requests_requester;
+DROP INDEX IF EXISTS uniq_join_requests_pending;
+ALTER TABLE join_requests DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE join_requests DROP COLUMN IF EXISTS decided_at;
+ALTER TABLE join_requests DROP COLUMN IF EXISTS decided_by;
+ALTER TABLE join_requests DROP COLUMN IF EXISTS decision_reason;
+UPDATE join_requests SET status = 'declined' WHERE status IN ('cancelled','expired');
+ALTER TABLE join_requests DROP CONSTRAINT IF EXISTS join_requests_status_check;
+ALTER TABLE join_requests ADD CONSTRAINT join_requests_status_check
+    CHECK (status IN ('pending','approved','declined'));
@@ -0,0 +1,20 @@
+ALTER TABLE join_requests DROP CONSTRAINT IF EXISTS join_requests_status_check;
+ALTER TABLE join_requests ADD CONSTRAINT join_requests_status_check
+    CHECK (status IN ('pending','approved','declined','cancelled','expired'));
+
+ALTER TABLE join_requests ADD COLUMN IF NOT EXISTS decision_reason TEXT;
+ALTER TABLE join_requests ADD COLUMN IF NOT EXISTS decided_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE join_requests ADD COLUMN IF NOT EXISTS decided_at TIMESTAMPTZ;
+ALTER TABLE join_requests ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+UPDATE join_requests SET expires_at = created_at + interval '30 days' WHERE expires_at IS NULL;
+ALTER TABLE join_requests ALTER COLUMN expires_at SET NOT NULL;
+
+-- Keep only the oldest pending request per (group, requester)
+UPDATE join_requests jr SET status = 'cancelled', decided_at = now()
+WHERE jr.status = 'pending' AND EXISTS (
+    SELECT 1 FROM join_requests o
+    WHERE o.group_id = jr.group_id AND o.requester_id = jr.requester_id AND o.status = 'pending' AND o.id < jr.id
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_join_requests_pending ON join_requests(group_id, requester_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_join_requests_requester ON join_requests(requester_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_join_requests_expiry ON join_requests(expires_at) WHERE status = 'pending';
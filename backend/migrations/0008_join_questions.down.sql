@@ -0,0 +1,3 @@
+ALTER TABLE join_requests DROP COLUMN IF EXISTS answers;
+DROP INDEX IF EXISTS idx_group_questions_group;
+DROP TABLE IF EXISTS group_questions;
@@ -0,0 +1,15 @@
+-- Screening questions asked of applicants to private groups
+CREATE TABLE IF NOT EXISTS group_questions (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    prompt TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('text','choice')),
+    options TEXT[] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT false,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_group_questions_group ON group_questions(group_id, position);
+
+-- Answers are stored with the request, including the prompt as asked
+ALTER TABLE join_requests ADD COLUMN IF NOT EXISTS answers JSONB NOT NULL DEFAULT '[]';
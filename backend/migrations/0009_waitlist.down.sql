@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_group_waitlist_queue;
+DROP TABLE IF EXISTS group_waitlist;
+ALTER TABLE groups DROP COLUMN IF EXISTS waitlist_enabled;
@@ -0,0 +1,11 @@
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS waitlist_enabled BOOLEAN NOT NULL DEFAULT false;
+
+-- FIFO queue of users waiting for a seat in a full open group
+CREATE TABLE IF NOT EXISTS group_waitlist (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (group_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_group_waitlist_queue ON group_waitlist(group_id, id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS ownership_transfer_events;
+DROP TABLE IF EXISTS ownership_transfers;
@@ -0,0 +1,25 @@
+-- Ownership transfers are offered to a member, who must accept before the deadline
+CREATE TABLE IF NOT EXISTS ownership_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    from_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    to_user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK (status IN ('pending','accepted','declined','cancelled','expired')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    decided_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_ownership_transfers_pending ON ownership_transfers(group_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_ownership_transfers_to ON ownership_transfers(to_user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_ownership_transfers_expiry ON ownership_transfers(expires_at) WHERE status = 'pending';
+
+-- Audit trail: one row per step of a transfer
+CREATE TABLE IF NOT EXISTS ownership_transfer_events (
+    id BIGSERIAL PRIMARY KEY,
+    transfer_id BIGINT NOT NULL REFERENCES ownership_transfers(id) ON DELETE CASCADE,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    event TEXT NOT NULL CHECK (event IN ('offered','accepted','declined','cancelled','expired')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_ownership_transfer_events_group ON ownership_transfer_events(group_id, id DESC);
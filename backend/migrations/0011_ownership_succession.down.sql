@@ -0,0 +1,5 @@
+ALTER TABLE groups DROP COLUMN IF EXISTS owner_inactive_days;
+ALTER TABLE groups DROP COLUMN IF EXISTS succession_rules;
+ALTER TABLE groups DROP COLUMN IF EXISTS successor_id;
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS last_seen_at;
@@ -0,0 +1,8 @@
+-- Account activity, used to detect inactive owners
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMPTZ;
+
+-- Succession: rules are tried in order until one yields an eligible member
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS successor_id BIGINT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS succession_rules TEXT[] NOT NULL DEFAULT '{designated,admin,member}';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS owner_inactive_days INT NOT NULL DEFAULT 0;
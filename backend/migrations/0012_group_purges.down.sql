@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_groups_deleted;
+DROP TABLE IF EXISTS group_purges;
@@ -0,0 +1,13 @@
+-- Audit record of hard-deleted groups; deliberately no foreign keys so the
+-- record outlives the rows it describes
+CREATE TABLE IF NOT EXISTS group_purges (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL,
+    name TEXT NOT NULL,
+    owner_id BIGINT NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL,
+    member_count INT NOT NULL,
+    message_count BIGINT NOT NULL,
+    purged_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_groups_deleted ON groups(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE groups DROP COLUMN IF EXISTS archived_by;
+ALTER TABLE groups DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,3 @@
+-- Archived groups are read-only: history stays readable, nothing new comes in
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS archived_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS group_invites;
+-- Secret groups fall back to private, the closest earlier type, keeping
+-- their members and messages
+UPDATE groups SET type = 'private' WHERE type = 'secret';
+ALTER TABLE groups DROP CONSTRAINT IF EXISTS groups_type_check;
+ALTER TABLE groups ADD CONSTRAINT groups_type_check CHECK (type IN ('open','private'));
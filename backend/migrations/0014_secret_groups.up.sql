@@ -0,0 +1,22 @@
+ALTER TABLE groups DROP CONSTRAINT IF EXISTS groups_type_check;
+ALTER TABLE groups ADD CONSTRAINT groups_type_check CHECK (type IN ('open','private','secret'));
+
+-- Invitations: a direct invite names the invitee; an invite link carries a
+-- token anyone holding it can redeem, up to max_uses times
+CREATE TABLE IF NOT EXISTS group_invites (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    inviter_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    invitee_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    token TEXT UNIQUE,
+    max_uses INT,
+    uses INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((invitee_id IS NULL) <> (token IS NULL))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_group_invites_open ON group_invites(group_id, invitee_id)
+    WHERE invitee_id IS NOT NULL AND accepted_at IS NULL AND revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_group_invites_invitee ON group_invites(invitee_id) WHERE invitee_id IS NOT NULL;
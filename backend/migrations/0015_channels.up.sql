@@ -0,0 +1,30 @@
+-- Channels split a group's conversation into separate message streams
+CREATE TABLE IF NOT EXISTS channels (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    restricted BOOLEAN NOT NULL DEFAULT false,
+    archived_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_channels_name ON channels(group_id, lower(name));
+CREATE INDEX IF NOT EXISTS idx_channels_group ON channels(group_id, position, id);
+
+-- Members of restricted channels; owners and admins see every channel
+CREATE TABLE IF NOT EXISTS channel_members (
+    channel_id BIGINT NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (channel_id, user_id)
+);
+
+-- Every existing group gets a default channel holding its history
+INSERT INTO channels (group_id, name, position)
+SELECT id, 'general', 0 FROM groups g WHERE NOT EXISTS (SELECT 1 FROM channels c WHERE c.group_id = g.id);
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS channel_id BIGINT REFERENCES channels(id) ON DELETE CASCADE;
+UPDATE messages m SET channel_id = (SELECT c.id FROM channels c WHERE c.group_id = m.group_id ORDER BY c.position, c.id LIMIT 1)
+WHERE channel_id IS NULL;
+ALTER TABLE messages ALTER COLUMN channel_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_channel_time ON messages(channel_id, created_at DESC);
@@ -0,0 +1,8 @@
+DELETE FROM membership_events WHERE event IN ('muted','unmuted');
+ALTER TABLE membership_events DROP CONSTRAINT IF EXISTS membership_events_event_check;
+ALTER TABLE membership_events ADD CONSTRAINT membership_events_event_check
+    CHECK (event IN ('joined','left','banished','unbanned','approved','role_changed'));
+ALTER TABLE group_members DROP COLUMN IF EXISTS muted_by;
+ALTER TABLE group_members DROP COLUMN IF EXISTS mute_reason;
+ALTER TABLE group_members DROP COLUMN IF EXISTS muted_until;
+ALTER TABLE groups DROP COLUMN IF EXISTS announcement_only;
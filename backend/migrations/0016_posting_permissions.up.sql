@@ -0,0 +1,11 @@
+-- Announcement mode: only owners and admins post
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS announcement_only BOOLEAN NOT NULL DEFAULT false;
+
+-- Per-member posting restrictions applied by moderators
+ALTER TABLE group_members ADD COLUMN IF NOT EXISTS muted_until TIMESTAMPTZ;
+ALTER TABLE group_members ADD COLUMN IF NOT EXISTS mute_reason TEXT;
+ALTER TABLE group_members ADD COLUMN IF NOT EXISTS muted_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE membership_events DROP CONSTRAINT IF EXISTS membership_events_event_check;
+ALTER TABLE membership_events ADD CONSTRAINT membership_events_event_check
+    CHECK (event IN ('joined','left','banished','unbanned','approved','role_changed','muted','unmuted'));
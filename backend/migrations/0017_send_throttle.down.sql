@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS send_throttle;
+ALTER TABLE groups DROP COLUMN IF EXISTS slow_mode_seconds;
@@ -0,0 +1,12 @@
+-- Slow mode: minimum seconds between messages from one member (0 = off)
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS slow_mode_seconds INT NOT NULL DEFAULT 0;
+
+-- Per-member send state shared by all replicas; one row per (group, sender)
+CREATE TABLE IF NOT EXISTS send_throttle (
+    group_id          BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id           BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_sent_at      TIMESTAMPTZ NOT NULL,
+    window_started_at TIMESTAMPTZ NOT NULL,
+    window_count      INT NOT NULL,
+    PRIMARY KEY (group_id, user_id)
+);
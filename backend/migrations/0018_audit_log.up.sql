@@ -0,0 +1,33 @@
+-- Moderation audit log: one row per privileged operation on a group
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    -- User ids carry no foreign keys: a SET NULL action would be an UPDATE,
+    -- which the append-only trigger refuses
+    actor_id BIGINT, -- NULL for system jobs
+    action TEXT NOT NULL,
+    target_user_id BIGINT,
+    target_id BIGINT, -- join request, channel, invite or transfer the action applies to
+    reason TEXT,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_group ON audit_log(group_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_group_action ON audit_log(group_id, action, id DESC);
+
+-- Entries are append-only. The one delete allowed is the cascade from a
+-- purged group: it runs nested inside the foreign key trigger, after the
+-- parent row is gone.
+CREATE OR REPLACE FUNCTION audit_log_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND pg_trigger_depth() > 1
+        AND NOT EXISTS (SELECT 1 FROM groups WHERE id = OLD.group_id) THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS audit_log_no_change ON audit_log;
+CREATE TRIGGER audit_log_no_change BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
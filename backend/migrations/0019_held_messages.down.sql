@@ -0,0 +1,19 @@
+CREATE OR REPLACE FUNCTION groups_touch_activity() RETURNS trigger AS $$
+BEGIN
+    UPDATE groups SET last_activity_at = NEW.created_at WHERE id = NEW.group_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS trg_messages_activity ON messages;
+CREATE TRIGGER trg_messages_activity AFTER INSERT ON messages
+    FOR EACH ROW EXECUTE FUNCTION groups_touch_activity();
+
+DELETE FROM messages WHERE status <> 'approved';
+DROP INDEX IF EXISTS idx_messages_sender_approved;
+DROP INDEX IF EXISTS idx_messages_held;
+ALTER TABLE messages DROP COLUMN IF EXISTS review_reason;
+ALTER TABLE messages DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE messages DROP COLUMN IF EXISTS status;
+ALTER TABLE groups DROP COLUMN IF EXISTS hold_min_approved_messages;
+ALTER TABLE groups DROP COLUMN IF EXISTS hold_new_member_hours;
@@ -0,0 +1,24 @@
+-- Hold messages from new members for review (0 disables each rule)
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS hold_new_member_hours INT NOT NULL DEFAULT 0;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS hold_min_approved_messages INT NOT NULL DEFAULT 0;
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'approved'
+    CHECK (status IN ('approved','held','rejected'));
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS reviewed_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS review_reason TEXT;
+CREATE INDEX IF NOT EXISTS idx_messages_held ON messages(group_id, id) WHERE status = 'held';
+CREATE INDEX IF NOT EXISTS idx_messages_sender_approved ON messages(group_id, sender_id) WHERE status = 'approved';
+
+-- Held messages are not activity until approved
+CREATE OR REPLACE FUNCTION groups_touch_activity() RETURNS trigger AS $$
+BEGIN
+    IF NEW.status = 'approved' THEN
+        UPDATE groups SET last_activity_at = GREATEST(last_activity_at, NEW.created_at) WHERE id = NEW.group_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS trg_messages_activity ON messages;
+CREATE TRIGGER trg_messages_activity AFTER INSERT OR UPDATE OF status ON messages
+    FOR EACH ROW EXECUTE FUNCTION groups_touch_activity();
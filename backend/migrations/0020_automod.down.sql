@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS automod_flags;
+DROP TABLE IF EXISTS automod_rules;
@@ -0,0 +1,24 @@
+-- Per-group automod rules evaluated against message plaintext before encryption
+CREATE TABLE IF NOT EXISTS automod_rules (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('keyword','regex','link_domain','mentions')),
+    pattern TEXT NOT NULL DEFAULT '', -- word, regex or domain; unused for mentions
+    threshold INT NOT NULL DEFAULT 0, -- mentions: fire above this many
+    action TEXT NOT NULL CHECK (action IN ('reject','hold','flag')),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    hit_count BIGINT NOT NULL DEFAULT 0,
+    last_hit_at TIMESTAMPTZ,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_automod_rules_group ON automod_rules(group_id, id);
+
+-- Messages published but flagged to moderators, with the rules that fired
+CREATE TABLE IF NOT EXISTS automod_flags (
+    message_id BIGINT PRIMARY KEY REFERENCES messages(id) ON DELETE CASCADE,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    rule_ids BIGINT[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_automod_flags_group ON automod_flags(group_id, message_id DESC);
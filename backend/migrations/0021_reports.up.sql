@@ -0,0 +1,29 @@
+-- Platform administrators review reports across all groups
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT false;
+
+-- Suspended accounts cannot sign in
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspension_reason TEXT;
+
+-- Abuse reports against a message, a user or a group
+CREATE TABLE IF NOT EXISTS reports (
+    id BIGSERIAL PRIMARY KEY,
+    reporter_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_type TEXT NOT NULL CHECK (target_type IN ('message','user','group')),
+    message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL, -- reported user, or the message sender
+    group_id BIGINT REFERENCES groups(id) ON DELETE CASCADE, -- where it happened, if anywhere
+    category TEXT NOT NULL CHECK (category IN ('spam','harassment','hate','violence','sexual','self_harm','illegal','other')),
+    comment TEXT,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','resolved')),
+    resolution TEXT CHECK (resolution IN ('dismiss','delete_message','banish','suspend')),
+    resolution_note TEXT,
+    resolved_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- One open report per reporter and target
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_reports_open ON reports(reporter_id, target_type, COALESCE(message_id,0), COALESCE(user_id,0), COALESCE(group_id,0))
+    WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_reports_group ON reports(group_id, status, id DESC);
+CREATE INDEX IF NOT EXISTS idx_reports_status ON reports(status, id DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS group_rules_acceptances;
+DROP TABLE IF EXISTS group_rules;
@@ -0,0 +1,20 @@
+-- Versioned group rules; each edit adds a version. An empty body means the
+-- group has no rules from that version on.
+CREATE TABLE IF NOT EXISTS group_rules (
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    body TEXT NOT NULL,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (group_id, version)
+);
+
+-- Members' acceptance of each rules version
+CREATE TABLE IF NOT EXISTS group_rules_acceptances (
+    group_id BIGINT NOT NULL,
+    version INT NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    accepted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (group_id, user_id, version),
+    FOREIGN KEY (group_id, version) REFERENCES group_rules(group_id, version) ON DELETE CASCADE
+);
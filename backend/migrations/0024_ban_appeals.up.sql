@@ -0,0 +1,15 @@
+-- Appeals from banned users; one open appeal per user and group
+CREATE TABLE IF NOT EXISTS ban_appeals (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','granted','denied')),
+    decided_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    decision_reason TEXT,
+    decided_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_ban_appeals_open ON ban_appeals(group_id, user_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_ban_appeals_group ON ban_appeals(group_id, status, id);
+CREATE INDEX IF NOT EXISTS idx_ban_appeals_user ON ban_appeals(user_id, id DESC);
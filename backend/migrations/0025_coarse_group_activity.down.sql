@@ -0,0 +1,8 @@
+CREATE OR REPLACE FUNCTION groups_touch_activity() RETURNS trigger AS $$
+BEGIN
+    IF NEW.status = 'approved' THEN
+        UPDATE groups SET last_activity_at = GREATEST(last_activity_at, NEW.created_at) WHERE id = NEW.group_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
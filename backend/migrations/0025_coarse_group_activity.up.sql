@@ -0,0 +1,14 @@
+-- Touch last_activity_at at most once a minute per group. Sends don't lock
+-- the group row, so bumping it on every message made busy groups contend
+-- with joins, approvals and other writers holding LockGroup. A row that
+-- fails the WHERE is skipped without taking its lock. member_count is left
+-- alone: membership changes already run under LockGroup.
+CREATE OR REPLACE FUNCTION groups_touch_activity() RETURNS trigger AS $$
+BEGIN
+    IF NEW.status = 'approved' THEN
+        UPDATE groups SET last_activity_at = NEW.created_at
+        WHERE id = NEW.group_id AND last_activity_at < NEW.created_at - interval '1 minute';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;